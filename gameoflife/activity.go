@@ -0,0 +1,145 @@
+package gameoflife
+
+// Side of the squares in which the generator tells the parts of the world
+// that change from the stable ones, which are not evaluated again
+const activityTileSize = 16
+
+type ActivityCounters struct {
+	// Cells whose next state was computed by the rules, and cells that were
+	// kept as they were, as nothing around them changed, over all the steps
+	EvaluatedCells, SkippedCells uint64
+
+	// Tiles that are evaluated in the next step
+	ActiveTiles int
+}
+
+// What happens to a cell in a step
+type cellStep struct {
+	coord Coord
+	next  Cell
+	kept  bool
+
+	// Cells to visit in the next step, as the cell is live
+	dependents []Coord
+
+	// Tiles to evaluate in the next step, as the cell changed
+	changedTiles []Coord
+
+	// Dead, but next to a live cell that is not evaluated
+	track bool
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+
+	return a / b
+}
+
+func tileOf(coord Coord) Coord {
+	return NewCoord(floorDiv(coord[0], activityTileSize), floorDiv(coord[1], activityTileSize))
+}
+
+// The tiles of the cell and of the ones that have it as a neighbour
+func (this *Generator) affectedTiles(coord Coord) []Coord {
+	tiles := []Coord{tileOf(coord)}
+
+	for _, n := range this.World.GetCellDependentsCoords(coord) {
+		tiles = append(tiles, tileOf(n))
+	}
+
+	return tiles
+}
+
+// Cells set from outside of the generator since the last step
+// make their tiles and the ones around active
+func (this *Generator) watchChanges() {
+	changes := this.World.changedCells
+	this.World.changedCells = make(map[Coord]bool)
+
+	if this.activeTiles == nil {
+		return
+	}
+
+	for coord := range changes {
+		for _, tile := range this.affectedTiles(coord) {
+			this.activeTiles[tile] = true
+		}
+	}
+}
+
+func (this *Generator) isStable(coord Coord) bool {
+	return this.activeTiles != nil && !this.activeTiles[tileOf(coord)]
+}
+
+// Keeps the cell as it is in the next generation
+func (this *Generator) skip(coord Coord) {
+	this.Counters.SkippedCells++
+
+	inactiveMatrix := this.World.GetInactiveMatrix()
+
+	if cell := this.World.ActiveMatrix.GetCell(coord); cell != DeadCell {
+		inactiveMatrix.SetCell(coord, cell)
+		return
+	}
+
+	inactiveMatrix.track(coord)
+}
+
+// Only reads the current generation, so cells can be evaluated concurrently
+func (this *Generator) evaluate(coord Coord) cellStep {
+	next, neighbours := this.nextCell(coord)
+
+	step := cellStep{coord: coord, next: next, kept: this.isKept(coord, next)}
+
+	if !step.kept {
+		next = DeadCell
+	}
+
+	if next != this.World.ActiveMatrix.GetCell(coord) {
+		step.changedTiles = this.affectedTiles(coord)
+	}
+
+	if next.IsLive() {
+		step.dependents = this.World.GetCellDependentsCoords(coord)
+	}
+
+	// The live cells that are skipped do not track the ones around them
+	if next == DeadCell {
+		for _, n := range neighbours {
+			if this.isStable(n) && this.World.ActiveMatrix.IsLive(n) {
+				step.track = true
+				break
+			}
+		}
+	}
+
+	return step
+}
+
+func (this *Generator) apply(step cellStep, tiles map[Coord]bool) {
+	this.Counters.EvaluatedCells++
+
+	inactiveMatrix := this.World.GetInactiveMatrix()
+
+	if step.kept {
+		inactiveMatrix.SetCell(step.coord, step.next)
+	} else if step.track {
+		inactiveMatrix.track(step.coord)
+	}
+
+	for _, n := range step.dependents {
+		inactiveMatrix.track(n)
+	}
+
+	for _, tile := range step.changedTiles {
+		tiles[tile] = true
+	}
+}
+
+func (this *Generator) finishStep(tiles map[Coord]bool) {
+	this.activeTiles = tiles
+	this.Counters.ActiveTiles = len(tiles)
+	this.World.SwapMatrices()
+}
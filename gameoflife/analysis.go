@@ -0,0 +1,312 @@
+package gameoflife
+
+import (
+	"fmt"
+)
+
+type WorldState string
+
+const (
+	EmptyWorld    WorldState = "empty"
+	StillWorld    WorldState = "still"
+	PeriodicWorld WorldState = "periodic"
+)
+
+// How the world settled down
+type Finding struct {
+	State WorldState
+
+	// From which generation on
+	Generation uint64
+
+	// 1 for still worlds, 0 for empty ones
+	Period uint64
+
+	// How much the cells move in each period, as spaceships do
+	Displacement Coord
+
+	// Only some generations were observed, so the real period may be
+	// smaller than Period, which it divides
+	Sampled bool
+}
+
+func (this Finding) String() string {
+	switch this.State {
+	case EmptyWorld:
+		return fmt.Sprintf("The world is empty from generation %d", this.Generation)
+	case StillWorld:
+		return fmt.Sprintf("The world is still from generation %d", this.Generation)
+	}
+
+	description := fmt.Sprintf("The world is periodic from generation %d, with period %d", this.Generation, this.Period)
+
+	if this.Sampled {
+		description = fmt.Sprintf("The world is still or periodic from generation %d, with a period dividing %d", this.Generation, this.Period)
+	}
+
+	if this.Moves() {
+		description += ", moving at " + this.Velocity()
+	}
+
+	return description
+}
+
+// Whether the cells are a spaceship, coming back somewhere else
+func (this Finding) Moves() bool {
+	return this.Displacement != NewCoord(0, 0)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// The speed in the usual c/p notation, as "c/4 diagonal" or "2c/5 orthogonal",
+// c being one cell per generation. Oblique ones give both displacements, as "(2,1)c/6"
+func (this Finding) Velocity() string {
+	dx, dy := abs(this.Displacement[0]), abs(this.Displacement[1])
+	period := int(this.Period)
+
+	if dx != 0 && dy != 0 && dx != dy {
+		if dx < dy {
+			dx, dy = dy, dx
+		}
+
+		return fmt.Sprintf("(%d,%d)c/%d oblique", dx, dy, period)
+	}
+
+	direction := "diagonal"
+
+	if dx == 0 || dy == 0 {
+		direction = "orthogonal"
+	}
+
+	// Only one of them is not 0 when orthogonal
+	distance := dx + dy
+
+	if dx == dy {
+		distance = dx
+	}
+
+	// 2c/4 is c/2
+	divisor := gcd(distance, period)
+	distance, period = distance/divisor, period/divisor
+
+	speed := "c"
+
+	if distance != 1 {
+		speed = fmt.Sprintf("%dc", distance)
+	}
+
+	if period != 1 {
+		speed += fmt.Sprintf("/%d", period)
+	}
+
+	return speed + " " + direction
+}
+
+const DefaultMaxPeriod = 1000
+
+// What is remembered of a generation
+type observation struct {
+	generation uint64
+	population uint64
+
+	// Two independent hashes of the cells, the second one
+	// confirming that generations with the same first one are equal
+	hash, check uint64
+
+	// The top left corner of the cells, and how far the bottom right one is
+	offset, size Coord
+}
+
+func (this observation) matches(other observation) bool {
+	return this.hash == other.hash && this.check == other.check && this.population == other.population && this.size == other.size
+}
+
+// Tells when the world becomes empty, still or periodic, by comparing each
+// generation with the previous ones, wherever their cells are, so that
+// moving patterns are found to be periodic too. Generations are compared
+// by two independent hashes of their cells, so that keeping all of them is
+// not needed, which could only both be wrong in extremely unlikely cases
+type PeriodDetector struct {
+	Universe Universe
+
+	// Longer periods are not found, as older generations are forgotten
+	MaxPeriod int
+
+	// The last generations, the newest one at the end
+	history []observation
+}
+
+func NewPeriodDetector(universe Universe) PeriodDetector {
+	return PeriodDetector{Universe: universe, MaxPeriod: DefaultMaxPeriod}
+}
+
+// Mixes the bits of the value, so that the sum of the values of
+// the cells changes with any of them
+func mix(value uint64) uint64 {
+	value ^= value >> 33
+	value *= 0xff51afd7ed558ccd
+	value ^= value >> 33
+	value *= 0xc4ceb9fe1a85ec53
+	value ^= value >> 33
+
+	return value
+}
+
+// Another mix, with other constants, so that both rarely go wrong together
+func mixAgain(value uint64) uint64 {
+	value ^= value >> 30
+	value *= 0xbf58476d1ce4e5b9
+	value ^= value >> 27
+	value *= 0x94d049bb133111eb
+	value ^= value >> 31
+
+	return value
+}
+
+func (this *PeriodDetector) observe(generation uint64) observation {
+	topLeft, bottomRight, _ := this.Universe.Bounds()
+
+	current := observation{generation: generation, offset: topLeft, size: NewCoord(bottomRight[0]-topLeft[0], bottomRight[1]-topLeft[1])}
+
+	// The order of the cells does not matter to a sum
+	this.Universe.ForEachCell(func(coord Coord, cell Cell) {
+		x, y := uint64(uint32(coord[0]-topLeft[0])), uint64(uint32(coord[1]-topLeft[1]))
+		value := x<<32 | y ^ uint64(cell)<<56
+
+		current.hash += mix(value)
+		current.check += mixAgain(value)
+		current.population++
+	})
+
+	return current
+}
+
+// With only some generations observed, the period found is a multiple of the
+// real one. A copy of the engine is stepped one generation at a time, at most
+// as many as that, until the cells are the same again, the engine itself being
+// left where it was. Other universes only tell the multiple
+func (this *PeriodDetector) refine(finding Finding, current observation) Finding {
+	engine, isEngine := this.Universe.(Engine)
+
+	if !isEngine {
+		finding.Sampled = true
+		return finding
+	}
+
+	copied := engine.Copy()
+	detector := NewPeriodDetector(copied)
+
+	for period := uint64(1); period <= finding.Period; period++ {
+		copied.StepN(1)
+
+		if next := detector.observe(current.generation + period); next.matches(current) {
+			finding.Period = period
+			finding.Displacement = NewCoord(next.offset[0]-current.offset[0], next.offset[1]-current.offset[1])
+			break
+		}
+	}
+
+	return finding
+}
+
+// To be called on each generation, from the first one, or every few of
+// them, telling what was found, if anything. Copies of the universes that
+// are engines are stepped further then, to find the real period
+func (this *PeriodDetector) Observe(generation uint64) (Finding, bool) {
+	current := this.observe(generation)
+
+	if current.population == 0 {
+		return Finding{State: EmptyWorld, Generation: generation}, true
+	}
+
+	for i := len(this.history) - 1; i >= 0; i-- {
+		previous := this.history[i]
+
+		if !previous.matches(current) {
+			continue
+		}
+
+		finding := Finding{
+			State:        PeriodicWorld,
+			Generation:   previous.generation,
+			Period:       generation - previous.generation,
+			Displacement: NewCoord(current.offset[0]-previous.offset[0], current.offset[1]-previous.offset[1]),
+		}
+
+		// Not all the generations in between were observed
+		if uint64(len(this.history)-i) != finding.Period {
+			finding = this.refine(finding, current)
+		}
+
+		if finding.Period == 1 && finding.Displacement == NewCoord(0, 0) {
+			finding.State = StillWorld
+		}
+
+		return finding, true
+	}
+
+	this.history = append(this.history, current)
+
+	if len(this.history) > this.MaxPeriod {
+		this.history = this.history[1:]
+	}
+
+	return Finding{}, false
+}
+
+const DefaultAnalysisGenerations = 10000
+
+// The grid a rule was made for, as hexagonal rules are
+func gridOf(ruleset Ruleset) Grid {
+	neighbourhood := ruleset.GetNeighbourhood()
+
+	if neighbourhood.Type == HexagonalNeighbourhood {
+		return HexagonalGrid
+	}
+
+	if neighbourhood.IsTriangular() {
+		return TriangularGrid
+	}
+
+	return SquareGrid
+}
+
+// Runs the pattern alone in an unbounded world, for at most the given
+// generations, telling what becomes of it, if anything is found
+func AnalysePattern(pattern Pattern, ruleset Ruleset, generations uint64) (Finding, bool, error) {
+	world := NewUnboundedWorld()
+	world.SetGrid(gridOf(ruleset))
+
+	placer := NewLifePlacer(&world)
+
+	if err := placer.Place(pattern.Specie, NewCoord(0, 0)); err != nil {
+		return Finding{}, false, err
+	}
+
+	engine := NewSparseEngine(&world, ruleset)
+	detector := NewPeriodDetector(&engine)
+
+	for generation := uint64(0); generation <= generations; generation++ {
+		if finding, found := detector.Observe(generation); found {
+			return finding, true, nil
+		}
+
+		engine.StepN(1)
+	}
+
+	return Finding{}, false, nil
+}
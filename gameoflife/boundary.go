@@ -0,0 +1,163 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// What is beyond an edge of the world
+type BoundaryCondition string
+
+const (
+	// Nothing, so cells on the edge have fewer neighbours. The default
+	ClipBoundary BoundaryCondition = "clip"
+
+	// The opposite edge, which must wrap as well
+	WrapBoundary BoundaryCondition = "wrap"
+
+	// The world itself, reflected on the edge
+	MirrorBoundary BoundaryCondition = "mirror"
+
+	// Cells that are always live or always dead
+	LiveBoundary BoundaryCondition = "live"
+	DeadBoundary BoundaryCondition = "dead"
+
+	// Nothing, as in clip, and cells cannot live on the edge either
+	AbsorbingBoundary BoundaryCondition = "absorbing"
+)
+
+// An empty condition means clip
+type Boundaries struct {
+	North, East, South, West BoundaryCondition
+}
+
+// Where all the cells beyond live and dead edges are, out of reach of any world
+var (
+	liveBorderCoord = NewCoord(math.MinInt32, math.MinInt32)
+	deadBorderCoord = NewCoord(math.MinInt32, math.MinInt32+1)
+)
+
+func (this *Boundaries) validate() error {
+	for _, condition := range []BoundaryCondition{this.North, this.East, this.South, this.West} {
+		switch condition {
+		case "", ClipBoundary, WrapBoundary, MirrorBoundary, LiveBoundary, DeadBoundary, AbsorbingBoundary:
+		default:
+			return errors.New(fmt.Sprintf("Unknown boundary condition \"%s\"", condition))
+		}
+	}
+
+	if (this.North == WrapBoundary) != (this.South == WrapBoundary) || (this.East == WrapBoundary) != (this.West == WrapBoundary) {
+		return errors.New("Wrapping edges must be opposite to another wrapping edge")
+	}
+
+	return nil
+}
+
+// A world with the given conditions on each of its edges. Cells beyond two
+// edges at once, as the corners, follow the east or west condition first
+func NewBoundedWorld(h, w int, boundaries Boundaries) (World, error) {
+	if err := boundaries.validate(); err != nil {
+		return World{}, err
+	}
+
+	// Where val ends up and, when it is still out of the world, what is there
+	axis := func(val, max int, low, high BoundaryCondition) (int, BoundaryCondition) {
+		condition := low
+
+		switch {
+		case val >= 0 && val < max:
+			return val, ""
+		case val >= max:
+			condition = high
+		}
+
+		switch condition {
+		case "":
+			return val, ClipBoundary
+		case WrapBoundary:
+			val, _ = wrap(val, max)
+			return val, ""
+		case MirrorBoundary:
+			// Reflecting again on the other edge when it is still out of the world
+			val, _ = wrap(val, 2*max)
+
+			if val >= max {
+				val = 2*max - 1 - val
+			}
+
+			return val, ""
+		}
+
+		return val, condition
+	}
+
+	world, err := NewGenericWorld(h, w, func(coord Coord) Coord {
+		x, y := coord.Get()
+
+		x, condition := axis(x, w, boundaries.West, boundaries.East)
+
+		if condition == "" {
+			y, condition = axis(y, h, boundaries.North, boundaries.South)
+		}
+
+		switch condition {
+		case LiveBoundary:
+			return liveBorderCoord
+		case DeadBoundary:
+			return deadBorderCoord
+		}
+
+		return NewCoord(x, y)
+	})
+
+	if err != nil {
+		return World{}, err
+	}
+
+	world.Boundaries = boundaries
+	world.fixedCells = map[Coord]Cell{liveBorderCoord: LiveCell, deadBorderCoord: DeadCell}
+
+	for coord, cell := range world.fixedCells {
+		world.ActiveMatrix.SetCell(coord, cell)
+	}
+
+	// Now that the borders are known
+	world.SetNeighbourhood(world.Neighbourhood)
+
+	return world, nil
+}
+
+func (this *World) liveBorderDependents() []Coord {
+	dependents := make([]Coord, 0)
+
+	if _, found := this.fixedCells[liveBorderCoord]; !found {
+		return dependents
+	}
+
+	h, w := this.Size()
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for _, n := range this.GetCellNeighboursCoords(NewCoord(x, y)) {
+				if n == liveBorderCoord {
+					dependents = append(dependents, NewCoord(x, y))
+					break
+				}
+			}
+		}
+	}
+
+	return dependents
+}
+
+// Whether the cell is on an absorbing edge, where cells cannot live
+func (this *World) IsAbsorbed(coord Coord) bool {
+	x, y := coord.Get()
+	h, w := this.Size()
+
+	return this.Boundaries.North == AbsorbingBoundary && y == 0 ||
+		this.Boundaries.South == AbsorbingBoundary && y == h-1 ||
+		this.Boundaries.West == AbsorbingBoundary && x == 0 ||
+		this.Boundaries.East == AbsorbingBoundary && x == w-1
+}
@@ -1,15 +1,35 @@
 package gameoflife
 
-type Cell bool
+// 0 is dead, 1 is live and any state above it is a dying
+// (refractory) one, as in the Generations rules
+type Cell uint8
+
+const (
+	DeadCell Cell = 0
+	LiveCell Cell = 1
+)
 
 func NewLiveCell() Cell {
-	return Cell(true)
+	return LiveCell
 }
 
 func NewDeadCell() Cell {
-	return Cell(false)
+	return DeadCell
 }
 
 func (this *Cell) IsLive() bool {
-	return bool(*this)
+	return *this == LiveCell
+}
+
+func (this *Cell) IsDying() bool {
+	return *this > LiveCell
+}
+
+// The state after this one, when there are the given number of states
+func (this *Cell) Decay(states int) Cell {
+	if int(*this)+1 >= states {
+		return DeadCell
+	}
+
+	return *this + 1
 }
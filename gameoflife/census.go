@@ -0,0 +1,515 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type ObjectKind string
+
+const (
+	StillLifeObject  ObjectKind = "still life"
+	OscillatorObject ObjectKind = "oscillator"
+	SpaceshipObject  ObjectKind = "spaceship"
+
+	// Not periodic on its own, as parts of objects close to others
+	UnknownObject ObjectKind = "unknown"
+)
+
+// Objects are followed on their own for this many generations at most
+const censusGenerations = 256
+
+// The names of some of the most common objects
+var objectNames = map[string]string{
+	"xs4_33":   "block",
+	"xs6_696":  "beehive",
+	"xs7_2596": "loaf",
+	"xs5_253":  "boat",
+	"xs6_356":  "ship",
+	"xs4_252":  "tub",
+	"xs8_6996": "pond",
+	"xp2_7":    "blinker",
+	"xp2_7e":   "toad",
+	"xp2_318c": "beacon",
+	"xq4_153":  "glider",
+	"xq4_6frc": "lightweight spaceship",
+}
+
+type CensusEntry struct {
+	// The canonical name of the object, as apgsearch gives it, as xs4_33 for the block
+	Code  string
+	Kind  ObjectKind
+	Count int
+}
+
+// The common name of the object, if it has one
+func (this CensusEntry) Name() string {
+	return objectNames[this.Code]
+}
+
+// The most common objects first
+type Census []CensusEntry
+
+func (this Census) String() string {
+	table := fmt.Sprintf("%8s  %-24s %-12s %s\n", "Count", "Object", "Kind", "Name")
+
+	for _, entry := range this {
+		table += fmt.Sprintf("%8d  %-24s %-12s %s\n", entry.Count, entry.Code, entry.Kind, entry.Name())
+	}
+
+	return table
+}
+
+// Cells this close to each other, in both directions, are taken as parts
+// of the same object, as apgsearch does
+var objectNeighbourhood = Neighbourhood{Type: MooreNeighbourhood, Range: 2}
+
+// The groups of live cells close to each other. Parts of some objects are
+// farther apart, which TakeCensus finds out by running them
+func (this *World) SeparateObjects() [][]Coord {
+	visited := make(map[Coord]bool)
+	objects := make([][]Coord, 0)
+
+	this.ForEachLiveCell(func(coord Coord) {
+		if visited[coord] {
+			return
+		}
+
+		visited[coord] = true
+
+		object := []Coord{coord}
+
+		for i := 0; i < len(object); i++ {
+			for _, n := range this.GetCellNeighboursCoordsIn(object[i], objectNeighbourhood) {
+				if !visited[n] && this.ActiveMatrix.IsLive(n) {
+					visited[n] = true
+					object = append(object, n)
+				}
+			}
+		}
+
+		objects = append(objects, object)
+	})
+
+	return objects
+}
+
+const wechslerDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// Runs of empty columns, shortened as w for 2, x for 3 and y for 4 to 39
+func writeWechslerZeros(builder *strings.Builder, zeros int) {
+	for zeros > 0 {
+		switch {
+		case zeros >= 4:
+			n := zeros - 4
+
+			if n > 35 {
+				n = 35
+			}
+
+			builder.WriteByte('y')
+			builder.WriteByte(wechslerDigits[n])
+			zeros -= n + 4
+		case zeros == 3:
+			builder.WriteByte('x')
+			zeros = 0
+		case zeros == 2:
+			builder.WriteByte('w')
+			zeros = 0
+		default:
+			builder.WriteByte('0')
+			zeros = 0
+		}
+	}
+}
+
+// The cells in the extended Wechsler format: strips of 5 rows separated by z,
+// each column of a strip being a digit whose bits are its cells, the top one first
+func wechsler(cells []Coord) string {
+	cells = normaliseCoords(cells)
+
+	live := make(map[Coord]bool)
+	h, w := 0, 0
+
+	for _, coord := range cells {
+		live[coord] = true
+
+		if coord[0] >= w {
+			w = coord[0] + 1
+		}
+
+		if coord[1] >= h {
+			h = coord[1] + 1
+		}
+	}
+
+	var builder strings.Builder
+
+	for top := 0; top < h; top += 5 {
+		if top > 0 {
+			builder.WriteByte('z')
+		}
+
+		// Trailing empty columns are left out
+		zeros := 0
+
+		for x := 0; x < w; x++ {
+			value := 0
+
+			for i := 0; i < 5; i++ {
+				if live[NewCoord(x, top+i)] {
+					value |= 1 << uint(i)
+				}
+			}
+
+			if value == 0 {
+				zeros++
+				continue
+			}
+
+			writeWechslerZeros(&builder, zeros)
+			zeros = 0
+
+			builder.WriteByte(wechslerDigits[value])
+		}
+	}
+
+	return builder.String()
+}
+
+// Moved so that the top left corner of the cells is the origin
+func normaliseCoords(cells []Coord) []Coord {
+	if len(cells) == 0 {
+		return cells
+	}
+
+	minX, minY := cells[0][0], cells[0][1]
+
+	for _, coord := range cells {
+		if coord[0] < minX {
+			minX = coord[0]
+		}
+
+		if coord[1] < minY {
+			minY = coord[1]
+		}
+	}
+
+	normalised := make([]Coord, len(cells))
+
+	for i, coord := range cells {
+		normalised[i] = NewCoord(coord[0]-minX, coord[1]-minY)
+	}
+
+	return normalised
+}
+
+// The shortest of the representations of all the phases in all the 8
+// orientations, the first in alphabetical order when they are as short
+func canonicalWechsler(phases [][]Coord) string {
+	best := ""
+
+	for _, cells := range phases {
+		for orientation := 0; orientation < 8; orientation++ {
+			oriented := make([]Coord, len(cells))
+
+			for i, coord := range cells {
+				x, y := coord[0], coord[1]
+
+				if orientation&1 != 0 {
+					x = -x
+				}
+
+				if orientation&2 != 0 {
+					y = -y
+				}
+
+				if orientation&4 != 0 {
+					x, y = y, x
+				}
+
+				oriented[i] = NewCoord(x, y)
+			}
+
+			code := wechsler(oriented)
+
+			if best == "" || len(code) < len(best) || len(code) == len(best) && code < best {
+				best = code
+			}
+		}
+	}
+
+	return best
+}
+
+type censusObject struct {
+	cells []Coord
+	kind  ObjectKind
+	code  string
+
+	// 0 for unknown objects
+	period uint64
+}
+
+// Follows the object on its own, to know what it is and to find its canonical code
+func classifyObject(cells []Coord, ruleset Ruleset) censusObject {
+	world := NewUnboundedWorld()
+
+	for _, coord := range cells {
+		world.ActivateCell(coord)
+	}
+
+	engine := NewSparseEngine(&world, ruleset)
+	detector := NewPeriodDetector(&engine)
+
+	phases := make([][]Coord, 0)
+
+	for generation := uint64(0); generation <= censusGenerations; generation++ {
+		finding, found := detector.Observe(generation)
+
+		// Objects in a settled world are periodic from the start
+		if found && (finding.State == EmptyWorld || finding.Generation != 0) {
+			break
+		}
+
+		if found {
+			switch {
+			case finding.State == StillWorld:
+				return censusObject{cells, StillLifeObject, fmt.Sprintf("xs%d_%s", len(cells), canonicalWechsler(phases)), 1}
+			case finding.Moves():
+				return censusObject{cells, SpaceshipObject, fmt.Sprintf("xq%d_%s", finding.Period, canonicalWechsler(phases)), finding.Period}
+			}
+
+			return censusObject{cells, OscillatorObject, fmt.Sprintf("xp%d_%s", finding.Period, canonicalWechsler(phases)), finding.Period}
+		}
+
+		phase := make([]Coord, 0, len(cells))
+
+		engine.ForEachLiveCell(func(coord Coord) {
+			phase = append(phase, coord)
+		})
+
+		phases = append(phases, phase)
+
+		engine.StepN(1)
+	}
+
+	return censusObject{cells, UnknownObject, fmt.Sprintf("zz%d_%s", len(cells), canonicalWechsler([][]Coord{cells})), 0}
+}
+
+func newObjectEngine(cells []Coord, ruleset Ruleset) SparseEngine {
+	world := NewUnboundedWorld()
+
+	for _, coord := range cells {
+		world.ActivateCell(coord)
+	}
+
+	return NewSparseEngine(&world, ruleset)
+}
+
+// Runs the objects all together and each on its own, for the given generations.
+// Where the two differ, the objects with cells around are parts of the same one.
+// Gives the objects to merge, by their indices, or false if they are all apart
+func interactingObjects(objects []censusObject, ruleset Ruleset, generations uint64) ([][]int, bool) {
+	all := make([]Coord, 0)
+
+	for _, object := range objects {
+		all = append(all, object.cells...)
+	}
+
+	whole := newObjectEngine(all, ruleset)
+	alone := make([]SparseEngine, len(objects))
+
+	for i, object := range objects {
+		alone[i] = newObjectEngine(object.cells, ruleset)
+	}
+
+	// Each object is merged into the one given by its parent, as in a disjoint-set forest
+	parents := make([]int, len(objects))
+
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var root func(i int) int
+
+	root = func(i int) int {
+		if parents[i] != i {
+			parents[i] = root(parents[i])
+		}
+
+		return parents[i]
+	}
+
+	for generation := uint64(0); generation < generations; generation++ {
+		owners := make(map[Coord][]int)
+
+		for i := range alone {
+			alone[i].ForEachLiveCell(func(coord Coord) {
+				owners[coord] = append(owners[coord], i)
+			})
+		}
+
+		whole.StepN(1)
+
+		union := make(map[Coord]bool)
+
+		for i := range alone {
+			alone[i].StepN(1)
+			alone[i].ForEachLiveCell(func(coord Coord) {
+				union[coord] = true
+			})
+		}
+
+		different := make([]Coord, 0)
+
+		whole.ForEachLiveCell(func(coord Coord) {
+			if !union[coord] {
+				different = append(different, coord)
+			}
+
+			delete(union, coord)
+		})
+
+		for coord := range union {
+			different = append(different, coord)
+		}
+
+		if len(different) == 0 {
+			continue
+		}
+
+		merged := false
+
+		for _, coord := range different {
+			around := append([]int{}, owners[coord]...)
+
+			for _, n := range whole.GetCellNeighboursCoords(coord) {
+				around = append(around, owners[n]...)
+			}
+
+			if len(around) == 0 {
+				continue
+			}
+
+			for _, i := range around {
+				if root(i) != root(around[0]) {
+					parents[root(i)] = root(around[0])
+					merged = true
+				}
+			}
+		}
+
+		if !merged {
+			return nil, false
+		}
+
+		groups := make(map[int][]int)
+		order := make([]int, 0)
+
+		for i := range objects {
+			r := root(i)
+
+			if _, seen := groups[r]; !seen {
+				order = append(order, r)
+			}
+
+			groups[r] = append(groups[r], i)
+		}
+
+		merges := make([][]int, 0, len(order))
+
+		for _, r := range order {
+			merges = append(merges, groups[r])
+		}
+
+		return merges, true
+	}
+
+	return nil, false
+}
+
+// What the world contains, as apgsearch tells it after a soup settles: the
+// live cells close to each other are grouped, and the groups that do not
+// evolve on their own over the period of the objects are merged. Then each
+// object is followed on its own. Objects that are not periodic on their own,
+// as those split by the edges of the world, are unknown
+func TakeCensus(universe Universe, ruleset Ruleset) (Census, error) {
+	if ruleset.States() > 2 || universe.GetGrid() != SquareGrid {
+		return nil, errors.New("The census only supports rules without dying states on the square grid")
+	}
+
+	// Objects are followed in an unbounded world, whatever the universe they are in
+	world := NewUnboundedWorld()
+
+	universe.ForEachLiveCell(func(coord Coord) {
+		world.ActivateCell(coord)
+	})
+
+	objects := make([]censusObject, 0)
+
+	for _, cells := range world.SeparateObjects() {
+		objects = append(objects, classifyObject(cells, ruleset))
+	}
+
+	for {
+		generations := uint64(1)
+
+		for _, object := range objects {
+			if object.period > generations {
+				generations = object.period
+			}
+		}
+
+		merges, found := interactingObjects(objects, ruleset, generations)
+
+		if !found {
+			break
+		}
+
+		merged := make([]censusObject, 0, len(merges))
+
+		for _, indices := range merges {
+			if len(indices) == 1 {
+				merged = append(merged, objects[indices[0]])
+				continue
+			}
+
+			cells := make([]Coord, 0)
+
+			for _, i := range indices {
+				cells = append(cells, objects[i].cells...)
+			}
+
+			merged = append(merged, classifyObject(cells, ruleset))
+		}
+
+		objects = merged
+	}
+
+	counts := make(map[string]int)
+	kinds := make(map[string]ObjectKind)
+
+	for _, object := range objects {
+		counts[object.code]++
+		kinds[object.code] = object.kind
+	}
+
+	census := make(Census, 0, len(counts))
+
+	for code, count := range counts {
+		census = append(census, CensusEntry{Code: code, Kind: kinds[code], Count: count})
+	}
+
+	sort.Slice(census, func(i, j int) bool {
+		if census[i].Count != census[j].Count {
+			return census[i].Count > census[j].Count
+		}
+
+		return census[i].Code < census[j].Code
+	})
+
+	return census, nil
+}
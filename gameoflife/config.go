@@ -20,12 +20,41 @@ type Config struct {
 
 	RandomCells int
 
-	Circular bool
+	// Either a name, as "torus", "klein", "cross-surface", "sphere" or "unbounded",
+	// or Golly's notation, as "T100,50+5", which replaces the size. In unbounded
+	// worlds, the size is the one of the part that is shown
+	Topology string
+
+	// What is beyond each edge of a plane world: "clip", the default,
+	// "wrap", "mirror", "live", "dead" or "absorbing"
+	Boundaries Boundaries
+
+	// "square", the default, "hexagonal" or "triangular"
+	Grid string
+
+	// As in B3/S23, the default when empty
+	Rule string
+
+	// Weights of the cells around the middle one, which is the cell itself,
+	// replacing the neighbourhood of the rule
+	Neighbourhood [][]int
+
+	// "sparse", the default, "dense", which is faster on crowded worlds
+	// but only runs life-like rules on square planes and tori, or "hashlife",
+	// for huge patterns and many generations, on unbounded worlds
+	Engine string
+
+	// 2^StepPow2 generations pass between two printed ones, which
+	// the hashlife engine computes at once
+	StepPow2 uint
+
+	// With the sparse engine, how many goroutines compute each generation, one when 0
+	Workers int
 
 	// a coordinate is an array with two elements
 	Positions [][2]int
 
-	Species map[string]Specie
+	Species map[string]Pattern
 
 	Population []struct {
 		Specie   string
@@ -0,0 +1,332 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Keeps the cells as bits, 64 in each word, counting the neighbours of
+// 64 cells at once. Only for life-like rules on the Moore neighbourhood,
+// in square worlds that are either planes or tori. The world itself is
+// only updated by Sync
+type DenseGenerator struct {
+	World *World
+	Rule  *LifeLikeRule
+
+	Circular bool
+
+	// One slice of words for each row, the bit i of the word k being the cell 64k + i
+	rows [][]uint64
+
+	// Where the next generation is written, before it is swapped with rows
+	next [][]uint64
+
+	// Reused on each step: the rows beyond a plane's edges, and the rows above,
+	// at and below the current one, shifted west and east
+	empty      []uint64
+	west, east [3][]uint64
+}
+
+const wordBits = 64
+
+// Whether the edges of the world wrap as in a torus, or are not there at all
+func denseTopology(world *World) (circular bool, ok bool) {
+	h, w := world.Size()
+
+	planar, toroidal := true, true
+
+	check := func(outside, inside Coord) {
+		t := world.NeighbourCoordTransformation(outside)
+
+		if _, fixed := world.fixedCells[t]; fixed || world.IsCoordValid(t) {
+			planar = false
+		}
+
+		if t != inside {
+			toroidal = false
+		}
+	}
+
+	for x := -1; x <= w; x++ {
+		check(NewCoord(x, -1), NewCoord((x+w)%w, h-1))
+		check(NewCoord(x, h), NewCoord((x+w)%w, 0))
+	}
+
+	for y := 0; y < h; y++ {
+		check(NewCoord(-1, y), NewCoord(w-1, y))
+		check(NewCoord(w, y), NewCoord(0, y))
+	}
+
+	return toroidal, planar || toroidal
+}
+
+func NewDenseGenerator(world *World, ruleset Ruleset) (DenseGenerator, error) {
+	rule, isLifeLike := ruleset.(*LifeLikeRule)
+
+	if !isLifeLike || rule.VonNeumann || rule.Hexagonal || rule.Triangular != "" {
+		return DenseGenerator{}, errors.New(fmt.Sprintf("The dense engine only supports life-like rules on the Moore neighbourhood, not %s", ruleset))
+	}
+
+	if world.Unbounded || world.Grid != SquareGrid || world.Boundaries != (Boundaries{}) {
+		return DenseGenerator{}, errors.New("The dense engine only supports square planes and tori")
+	}
+
+	circular, ok := denseTopology(world)
+
+	if !ok {
+		return DenseGenerator{}, errors.New("The dense engine only supports square planes and tori")
+	}
+
+	generator := newDenseGenerator(world, rule, circular)
+
+	world.ForEachCoordinate(func(coord Coord) {
+		if world.ActiveMatrix.IsLive(coord) {
+			x, y := coord.Get()
+			generator.rows[y][x/wordBits] |= 1 << uint(x%wordBits)
+		}
+	})
+
+	return generator, nil
+}
+
+// With all the cells dead
+func newDenseGenerator(world *World, rule *LifeLikeRule, circular bool) DenseGenerator {
+	h, w := world.Size()
+	words := (w + wordBits - 1) / wordBits
+
+	grid := func() [][]uint64 {
+		rows := make([][]uint64, h)
+
+		for y := range rows {
+			rows[y] = make([]uint64, words)
+		}
+
+		return rows
+	}
+
+	generator := DenseGenerator{World: world, Rule: rule, Circular: circular, rows: grid(), next: grid(), empty: make([]uint64, words)}
+
+	for i := range generator.west {
+		generator.west[i], generator.east[i] = make([]uint64, words), make([]uint64, words)
+	}
+
+	return generator
+}
+
+// The row shifted so that each cell gets the state of the cell on its west,
+// and on its east, written into the given slices
+func (this *DenseGenerator) shifted(row, west, east []uint64) {
+	_, w := this.World.Size()
+
+	for k := range row {
+		west[k] = row[k] << 1
+		east[k] = row[k] >> 1
+
+		if k > 0 {
+			west[k] |= row[k-1] >> (wordBits - 1)
+		}
+
+		if k+1 < len(row) {
+			east[k] |= row[k+1] << (wordBits - 1)
+		}
+	}
+
+	if this.Circular {
+		last := uint((w - 1) % wordBits)
+
+		west[0] |= (row[len(row)-1] >> last) & 1
+		east[len(row)-1] |= (row[0] & 1) << last
+	}
+}
+
+func (this *DenseGenerator) Step() {
+	h, w := this.World.Size()
+
+	words := (w + wordBits - 1) / wordBits
+
+	// The bits beyond the width in the last word must stay clear
+	lastMask := ^uint64(0)
+
+	if w%wordBits != 0 {
+		lastMask = 1<<uint(w%wordBits) - 1
+	}
+
+	row := func(y int) []uint64 {
+		if y >= 0 && y < h {
+			return this.rows[y]
+		}
+
+		if this.Circular {
+			return this.rows[(y+h)%h]
+		}
+
+		return this.empty
+	}
+
+	next := this.next
+
+	for y := 0; y < h; y++ {
+		above, current, below := row(y-1), row(y), row(y+1)
+
+		this.shifted(above, this.west[0], this.east[0])
+		this.shifted(current, this.west[1], this.east[1])
+		this.shifted(below, this.west[2], this.east[2])
+
+		aboveWest, aboveEast := this.west[0], this.east[0]
+		currentWest, currentEast := this.west[1], this.east[1]
+		belowWest, belowEast := this.west[2], this.east[2]
+
+		for k := 0; k < words; k++ {
+			// The number of live neighbours of each of the 64 cells, bit by bit
+			var count [4]uint64
+
+			for _, neighbours := range [8]uint64{aboveWest[k], above[k], aboveEast[k], currentWest[k], currentEast[k], belowWest[k], below[k], belowEast[k]} {
+				carry := neighbours
+
+				for bit := 0; bit < len(count) && carry != 0; bit++ {
+					count[bit], carry = count[bit]^carry, count[bit]&carry
+				}
+			}
+
+			cells := current[k]
+
+			var result uint64
+
+			for n := 0; n <= 8; n++ {
+				if !this.Rule.Birth[n] && !this.Rule.Survival[n] {
+					continue
+				}
+
+				withN := ^uint64(0)
+
+				for bit := range count {
+					if n&(1<<uint(bit)) != 0 {
+						withN &= count[bit]
+					} else {
+						withN &= ^count[bit]
+					}
+				}
+
+				if this.Rule.Birth[n] {
+					result |= withN &^ cells
+				}
+
+				if this.Rule.Survival[n] {
+					result |= withN & cells
+				}
+			}
+
+			next[y][k] = result
+		}
+
+		next[y][words-1] &= lastMask
+	}
+
+	this.rows, this.next = next, this.rows
+}
+
+// Writes the cells into the world, so they can be printed or exported
+func (this *DenseGenerator) Sync() {
+	this.World.ActiveMatrix = CreateMatrix()
+	this.World.InactiveMatrix = CreateMatrix()
+
+	this.ForEachLiveCell(func(coord Coord) {
+		this.World.SetCell(coord, LiveCell)
+	})
+}
+
+func (this *DenseGenerator) StepN(n uint64) {
+	for i := uint64(0); i < n; i++ {
+		this.Step()
+	}
+}
+
+// Sharing the world, which only Sync writes to
+func (this *DenseGenerator) Copy() Engine {
+	generator := newDenseGenerator(this.World, this.Rule, this.Circular)
+
+	for y, row := range this.rows {
+		copy(generator.rows[y], row)
+	}
+
+	return &generator
+}
+
+func (this *DenseGenerator) Size() (h, w int) {
+	return this.World.Size()
+}
+
+func (this *DenseGenerator) GetGrid() Grid {
+	return SquareGrid
+}
+
+func (this *DenseGenerator) IsCoordValid(coord Coord) bool {
+	return this.World.IsCoordValid(coord)
+}
+
+func (this *DenseGenerator) GetCell(coord Coord) (Cell, error) {
+	if !this.IsCoordValid(coord) {
+		return DeadCell, errors.New("Invalid coord")
+	}
+
+	x, y := coord.Get()
+
+	if this.rows[y][x/wordBits]&(1<<uint(x%wordBits)) != 0 {
+		return LiveCell, nil
+	}
+
+	return DeadCell, nil
+}
+
+// Cells in any state other than dead are live
+func (this *DenseGenerator) SetCell(coord Coord, cell Cell) error {
+	if !this.IsCoordValid(coord) {
+		return errors.New("Invalid coord")
+	}
+
+	x, y := coord.Get()
+	bit := uint64(1) << uint(x%wordBits)
+
+	if cell == DeadCell {
+		this.rows[y][x/wordBits] &^= bit
+	} else {
+		this.rows[y][x/wordBits] |= bit
+	}
+
+	return nil
+}
+
+func (this *DenseGenerator) ForEachLiveCell(f func(Coord)) {
+	for y, row := range this.rows {
+		for k, word := range row {
+			for bit := 0; word != 0; bit, word = bit+1, word>>1 {
+				if word&1 != 0 {
+					f(NewCoord(k*wordBits+bit, y))
+				}
+			}
+		}
+	}
+}
+
+func (this *DenseGenerator) ForEachCell(f func(Coord, Cell)) {
+	this.ForEachLiveCell(func(coord Coord) {
+		f(coord, LiveCell)
+	})
+}
+
+func (this *DenseGenerator) Population() uint64 {
+	population := 0
+
+	for _, row := range this.rows {
+		for _, word := range row {
+			population += bits.OnesCount64(word)
+		}
+	}
+
+	return uint64(population)
+}
+
+func (this *DenseGenerator) Bounds() (topLeft, bottomRight Coord, found bool) {
+	return boundsOfLiveCells(this)
+}
@@ -0,0 +1,431 @@
+package gameoflife
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// The live cells of a universe, relative to the top left corner of their bounds
+type normalisedCells struct {
+	offset Coord
+	cells  map[Coord]bool
+}
+
+func liveCellsOf(universe Universe) map[Coord]bool {
+	cells := make(map[Coord]bool)
+
+	universe.ForEachLiveCell(func(coord Coord) {
+		cells[coord] = true
+	})
+
+	return cells
+}
+
+// The cells that are not dead, in whatever state they are
+func cellsOf(universe Universe) map[Coord]Cell {
+	cells := make(map[Coord]Cell)
+
+	universe.ForEachCell(func(coord Coord, cell Cell) {
+		cells[coord] = cell
+	})
+
+	return cells
+}
+
+func normalise(universe Universe) normalisedCells {
+	topLeft, _, _ := universe.Bounds()
+
+	cells := make(map[Coord]bool)
+
+	universe.ForEachLiveCell(func(coord Coord) {
+		cells[NewCoord(coord[0]-topLeft[0], coord[1]-topLeft[1])] = true
+	})
+
+	return normalisedCells{topLeft, cells}
+}
+
+type differentialCase struct {
+	name  string
+	grid  Grid
+	world func() World
+
+	// Engines that run on the world, besides the sparse ones, which run everywhere
+	engines []string
+}
+
+// An engine by name, "parallel" being the sparse one with 4 workers
+func newDifferentialEngine(name string, world *World, ruleset Ruleset) Engine {
+	if name == "parallel" {
+		engine := NewSparseEngine(world, ruleset)
+		engine.Generator.Workers = 4
+		return &engine
+	}
+
+	engine, err := NewEngine(name, world, ruleset)
+	So(err, ShouldEqual, nil)
+
+	return engine
+}
+
+// Computes each generation the plain way, visiting every cell of the world,
+// or of the bounds of the cells and around them in unbounded worlds. It only
+// shares with the engines how the world tells the neighbours of a cell
+type bruteForceReference struct {
+	world   *World
+	ruleset Ruleset
+}
+
+func newBruteForceReference(world *World, ruleset Ruleset) bruteForceReference {
+	world.SetNeighbourhood(ruleset.GetNeighbourhood())
+	return bruteForceReference{world, ruleset}
+}
+
+func (this *bruteForceReference) next(coord Coord) Cell {
+	matrix := this.world.ActiveMatrix
+	cell := matrix.GetCell(coord)
+	states := this.ruleset.States()
+
+	if this.world.IsAbsorbed(coord) {
+		return DeadCell
+	}
+
+	if cell.IsDying() {
+		return cell.Decay(states)
+	}
+
+	ruleset := this.ruleset
+
+	if generations, isGenerations := ruleset.(*GenerationsRule); isGenerations {
+		ruleset = generations.Ruleset
+	}
+
+	live := cell.IsLive()
+	count := 0
+
+	for _, n := range this.world.GetCellNeighboursCoords(coord) {
+		if matrix.IsLive(n) {
+			count++
+		}
+	}
+
+	becomesLive := false
+
+	switch rule := ruleset.(type) {
+	case *LifeLikeRule:
+		becomesLive = live && rule.Survival[count] || !live && rule.Birth[count]
+	case *IsotropicRule:
+		configuration := this.world.GetCellNeighbourhoodConfiguration(coord)
+		becomesLive = live && rule.Survival[configuration] || !live && rule.Birth[configuration]
+	case *LargerThanLifeRule:
+		ranges := rule.Birth
+
+		if live {
+			ranges = rule.Survival
+
+			if rule.Middle {
+				count++
+			}
+		}
+
+		for _, r := range ranges {
+			becomesLive = becomesLive || r.Contains(count)
+		}
+	default:
+		panic("No reference for rule " + ruleset.String())
+	}
+
+	switch {
+	case becomesLive:
+		return LiveCell
+	case live:
+		return cell.Decay(states)
+	}
+
+	return DeadCell
+}
+
+func (this *bruteForceReference) step() {
+	next := CreateMatrix()
+
+	for coord, cell := range this.world.fixedCells {
+		next[coord] = cell
+	}
+
+	visit := func(coord Coord) {
+		if cell := this.next(coord); cell != DeadCell {
+			next[coord] = cell
+		}
+	}
+
+	if this.world.Unbounded {
+		// As far as cells can be born
+		margin := 2 * this.world.Neighbourhood.Range
+
+		if topLeft, bottomRight, found := this.world.Bounds(); found {
+			for y := topLeft[1] - margin; y <= bottomRight[1]+margin; y++ {
+				for x := topLeft[0] - margin; x <= bottomRight[0]+margin; x++ {
+					visit(NewCoord(x, y))
+				}
+			}
+		}
+	} else {
+		h, w := this.world.Size()
+
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				visit(NewCoord(x, y))
+			}
+		}
+	}
+
+	this.world.ActiveMatrix = next
+}
+
+// Those that are not dead, in whatever state they are
+func (this *bruteForceReference) cells() map[Coord]Cell {
+	cells := make(map[Coord]Cell)
+
+	for coord, cell := range this.world.ActiveMatrix {
+		if _, fixed := this.world.fixedCells[coord]; !fixed && cell != DeadCell {
+			cells[coord] = cell
+		}
+	}
+
+	return cells
+}
+
+// The dense and the HashLife engines only run life-like rules on the Moore neighbourhood
+func runsOn(name string, ruleset Ruleset) bool {
+	if name != "dense" && name != "hashlife" {
+		return true
+	}
+
+	lifeLike, isLifeLike := ruleset.(*LifeLikeRule)
+
+	return isLifeLike && lifeLike.GetNeighbourhood().Type == MooreNeighbourhood
+}
+
+func TestEnginesAgree(t *testing.T) {
+	cases := []differentialCase{
+		{"plane", SquareGrid, func() World {
+			world, _ := NewWorld(48, 80)
+			return world
+		}, []string{"dense"}},
+		{"torus", SquareGrid, func() World {
+			world, _ := NewCircularWorld(48, 80)
+			return world
+		}, []string{"dense"}},
+		{"unbounded", SquareGrid, NewUnboundedWorld, []string{"hashlife"}},
+		{"klein bottle", SquareGrid, func() World {
+			world, _ := NewKleinBottleWorld(48, 80, false)
+			return world
+		}, nil},
+		{"cross-surface", SquareGrid, func() World {
+			world, _ := NewCrossSurfaceWorld(48, 80)
+			return world
+		}, nil},
+		{"sphere", SquareGrid, func() World {
+			world, _ := NewSphereWorld(64, 64)
+			return world
+		}, nil},
+		{"shifted torus", SquareGrid, func() World {
+			world, _ := NewShiftedTorusWorld(48, 80, 7, 0)
+			return world
+		}, nil},
+		{"bounded", SquareGrid, func() World {
+			world, _ := NewBoundedWorld(48, 80, Boundaries{North: MirrorBoundary, East: LiveBoundary, South: AbsorbingBoundary, West: DeadBoundary})
+			return world
+		}, nil},
+		{"hexagonal torus", HexagonalGrid, func() World {
+			world, _ := NewCircularWorld(48, 80)
+			return world
+		}, nil},
+		{"triangular plane", TriangularGrid, func() World {
+			world, _ := NewWorld(48, 80)
+			return world
+		}, nil},
+		{"triangular torus", TriangularGrid, func() World {
+			world, _ := NewCircularWorld(48, 80)
+			return world
+		}, nil},
+	}
+
+	// Of each family: totalistic, Generations, isotropic and Larger than Life
+	rules := map[Grid][]string{
+		SquareGrid:     {"B3/S23", "B36/S23", "B3678/S34678", "B2/S", "B2/S/C3", "B2-a/S12", "R2,C0,M1,S5..8,B5..6,NM"},
+		HexagonalGrid:  {"B2/S34H", "B2/S34H/C4"},
+		TriangularGrid: {"B4/S345L", "B2/S2LE", "B45/S3456LV/C3"},
+	}
+
+	// Those explode, so fewer of their generations are compared
+	explosive := map[string]bool{"B2/S": true, "B2/S/C3": true}
+
+	soup := func(world *World, seed int64) {
+		random := rand.New(rand.NewSource(seed))
+
+		// All over the part of the world all of them have, so that the edges matter
+		for i := 0; i < 1000; i++ {
+			world.ActivateCell(NewCoord(random.Intn(64), random.Intn(48)))
+		}
+	}
+
+	// Each combination with a soup of its own
+	seed := int64(0)
+
+	for _, c := range cases {
+		for _, rule := range rules[c.grid] {
+			seed++
+
+			Convey(fmt.Sprintf("%s, %s, soup %d", c.name, rule, seed), t, func() {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+
+				newWorld := func() World {
+					world := c.world()
+					world.SetGrid(c.grid)
+					soup(&world, seed)
+					return world
+				}
+
+				referenceWorld := newWorld()
+				reference := newBruteForceReference(&referenceWorld, ruleset)
+
+				names := make([]string, 0)
+				engines := make([]Engine, 0)
+
+				for _, name := range append([]string{"sparse", "parallel"}, c.engines...) {
+					if !runsOn(name, ruleset) {
+						continue
+					}
+
+					world := newWorld()
+					names = append(names, name)
+					engines = append(engines, newDifferentialEngine(name, &world, ruleset))
+				}
+
+				generations := 25
+
+				if explosive[rule] {
+					generations = 10
+				}
+
+				for generation := 1; generation <= generations; generation++ {
+					reference.step()
+					expected := reference.cells()
+
+					for i, engine := range engines {
+						engine.StepN(1)
+
+						// Telling which engine and generation, rather than dumping the cells
+						if !reflect.DeepEqual(cellsOf(engine), expected) {
+							So(fmt.Sprintf("The %s engine differs on generation %d", names[i], generation), ShouldBeEmpty)
+						}
+					}
+				}
+
+				for _, engine := range engines {
+					So(engine.Population(), ShouldEqual, referenceWorld.Population())
+				}
+			})
+		}
+	}
+}
+
+// Well known patterns, in testdata/golden, and what is known about them
+type goldenPattern struct {
+	file string
+
+	// 0 when the pattern does not repeat itself
+	period int
+
+	// How much the pattern moves in each period
+	displacement Coord
+
+	// Known populations, by generation
+	populations map[int]uint64
+}
+
+func TestGoldenCorpus(t *testing.T) {
+	corpus := []goldenPattern{
+		{"glider.rle", 4, NewCoord(1, 1), map[int]uint64{0: 5, 1: 5, 2: 5, 3: 5, 400: 5}},
+		{"lwss.rle", 4, NewCoord(-2, 0), map[int]uint64{0: 9, 1: 12, 2: 9, 3: 12, 400: 9}},
+		{"pulsar.rle", 3, NewCoord(0, 0), map[int]uint64{0: 48, 1: 56, 2: 72, 3: 48, 300: 48}},
+		{"gosperglidergun.rle", 0, NewCoord(0, 0), map[int]uint64{0: 36, 30: 41, 300: 86, 600: 136}},
+		{"rpentomino.rle", 0, NewCoord(0, 0), map[int]uint64{0: 5, 1: 6, 1103: 116, 1500: 116}},
+	}
+
+	conway, _ := ParseRule(DefaultRule)
+	importer := NewSpecieImporter()
+
+	for _, golden := range corpus {
+		for _, engineName := range []string{"sparse", "parallel", "hashlife"} {
+			Convey(golden.file+" on the "+engineName+" engine", t, func() {
+				content, err := ioutil.ReadFile(filepath.Join("testdata", "golden", golden.file))
+				So(err, ShouldEqual, nil)
+
+				pattern, err := importer.ImportFromRLEString(string(content))
+				So(err, ShouldEqual, nil)
+				So(pattern.RunsUnderRule(conway), ShouldBeTrue)
+
+				world := NewUnboundedWorld()
+				placer := NewLifePlacer(&world)
+				So(placer.Place(pattern.Specie, NewCoord(0, 0)), ShouldEqual, nil)
+
+				engine := newDifferentialEngine(engineName, &world, conway)
+
+				last := 0
+
+				for _, generation := range sortedGenerations(golden.populations) {
+					engine.StepN(uint64(generation - last))
+					last = generation
+
+					So(engine.Population(), ShouldEqual, golden.populations[generation])
+				}
+
+				if golden.period == 0 {
+					return
+				}
+
+				// Back to the first generation, to find the smallest period
+				world = NewUnboundedWorld()
+				placer = NewLifePlacer(&world)
+				placer.Place(pattern.Specie, NewCoord(0, 0))
+				engine = newDifferentialEngine(engineName, &world, conway)
+
+				first := normalise(engine)
+
+				for generation := 1; generation <= golden.period; generation++ {
+					engine.StepN(1)
+					current := normalise(engine)
+
+					if generation < golden.period {
+						So(current.cells, ShouldNotResemble, first.cells)
+						continue
+					}
+
+					So(current.cells, ShouldResemble, first.cells)
+					So(NewCoord(current.offset[0]-first.offset[0], current.offset[1]-first.offset[1]), ShouldResemble, golden.displacement)
+				}
+			})
+		}
+	}
+}
+
+func sortedGenerations(populations map[int]uint64) []int {
+	generations := make([]int, 0, len(populations))
+
+	for generation := range populations {
+		generations = append(generations, generation)
+	}
+
+	sort.Ints(generations)
+
+	return generations
+}
@@ -0,0 +1,119 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Where the cells are, whatever keeps them, so that printing, placing and
+// exporting patterns work the same way on all the engines
+type Universe interface {
+	// Both 0 when the universe is unbounded
+	Size() (h, w int)
+
+	GetGrid() Grid
+
+	IsCoordValid(coord Coord) bool
+
+	GetCell(coord Coord) (Cell, error)
+	SetCell(coord Coord, cell Cell) error
+
+	// Only the cells in the live state, not the dying ones
+	ForEachLiveCell(f func(coord Coord))
+
+	// All the cells that are not dead, dying ones included
+	ForEachCell(f func(coord Coord, cell Cell))
+	Population() uint64
+
+	// The smallest rectangle with all the cells that are not dead,
+	// given by its top left and bottom right corners
+	Bounds() (topLeft, bottomRight Coord, found bool)
+}
+
+// A universe that knows how to move its generations ahead
+type Engine interface {
+	Universe
+
+	StepN(n uint64)
+
+	// Another engine with the same cells, to be stepped apart from this one
+	Copy() Engine
+}
+
+// The world as a map of the cells worth visiting, stepped by a Generator.
+// It runs any rule on any grid and topology
+type SparseEngine struct {
+	*World
+	Generator Generator
+
+	ruleset Ruleset
+}
+
+func NewSparseEngine(world *World, ruleset Ruleset) SparseEngine {
+	return SparseEngine{world, NewRulesetGenerator(world, ruleset), ruleset}
+}
+
+// On a world of its own, as the rules are bound to the world they run on
+func (this *SparseEngine) Copy() Engine {
+	world := this.World.Copy()
+
+	engine := NewSparseEngine(&world, this.ruleset)
+	engine.Generator.Workers = this.Generator.Workers
+
+	return &engine
+}
+
+func (this *SparseEngine) StepN(n uint64) {
+	for i := uint64(0); i < n; i++ {
+		this.Generator.Step()
+	}
+}
+
+// For the universes where all the cells that are not dead are live
+func boundsOfLiveCells(universe Universe) (topLeft, bottomRight Coord, found bool) {
+	universe.ForEachLiveCell(func(coord Coord) {
+		if !found {
+			topLeft, bottomRight, found = coord, coord, true
+			return
+		}
+
+		x, y := coord.Get()
+
+		if x < topLeft[0] {
+			topLeft[0] = x
+		}
+
+		if y < topLeft[1] {
+			topLeft[1] = y
+		}
+
+		if x > bottomRight[0] {
+			bottomRight[0] = x
+		}
+
+		if y > bottomRight[1] {
+			bottomRight[1] = y
+		}
+	})
+
+	return topLeft, bottomRight, found
+}
+
+// The engine by the name it has in the configuration, running the world.
+// Engines other than the sparse one keep their own copy of the cells,
+// and only write them back into the world when synced
+func NewEngine(name string, world *World, ruleset Ruleset) (Engine, error) {
+	switch name {
+	case "", "sparse":
+		engine := NewSparseEngine(world, ruleset)
+		return &engine, nil
+	case "dense":
+		engine, err := NewDenseGenerator(world, ruleset)
+		return &engine, err
+	case "hashlife":
+		engine, err := NewHashLifeGenerator(world, ruleset)
+		return &engine, err
+	}
+
+	return nil, errors.New(fmt.Sprintf("Unknown engine \"%s\"", name))
+}
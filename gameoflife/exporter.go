@@ -0,0 +1,257 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RLE lines should not be longer than that
+const rleLineLength = 70
+
+type Exporter struct {
+	Universe Universe
+}
+
+func NewExporter(universe Universe) Exporter {
+	return Exporter{universe}
+}
+
+// The smallest pattern containing all the live cells in the universe
+func (this *Exporter) Capture() (Pattern, error) {
+	cells := make([]Coord, 0)
+
+	this.Universe.ForEachLiveCell(func(coord Coord) {
+		cells = append(cells, coord)
+	})
+
+	specie, err := specieFromCoords(cells)
+
+	if err != nil {
+		return Pattern{}, errors.New("The world is empty")
+	}
+
+	return NewPattern(specie), nil
+}
+
+// The pattern in the h x w rectangle whose top left corner is coord
+func (this *Exporter) CaptureRegion(coord Coord, h, w int) (Pattern, error) {
+	x, y := coord.Get()
+
+	if h <= 0 || w <= 0 || !this.Universe.IsCoordValid(coord) || !this.Universe.IsCoordValid(NewCoord(x+w-1, y+h-1)) {
+		return Pattern{}, errors.New("Invalid region")
+	}
+
+	rows := make([][]int, h)
+
+	for i := range rows {
+		rows[i] = make([]int, w)
+
+		for j := range rows[i] {
+			if cell, _ := this.Universe.GetCell(NewCoord(x+j, y+i)); cell.IsLive() {
+				rows[i][j] = 1
+			}
+		}
+	}
+
+	specie, err := NewSpecie(rows)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	return NewPattern(specie), nil
+}
+
+func (this *Exporter) Export(format PatternFormat) (string, error) {
+	pattern, err := this.Capture()
+
+	if err != nil {
+		return "", err
+	}
+
+	return ExportPattern(format, pattern)
+}
+
+func (this *Exporter) ExportRegion(format PatternFormat, coord Coord, h, w int) (string, error) {
+	pattern, err := this.CaptureRegion(coord, h, w)
+
+	if err != nil {
+		return "", err
+	}
+
+	return ExportPattern(format, pattern)
+}
+
+func ExportPattern(format PatternFormat, pattern Pattern) (string, error) {
+	switch format {
+	case PlaintextFormat:
+		return PatternToPlaintext(pattern), nil
+	case Life106Format:
+		return PatternToLife106(pattern), nil
+	case RLEFormat:
+		return PatternToRLE(pattern), nil
+	case MacrocellFormat:
+		return PatternToMacrocell(pattern), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("Exporting %s patterns is not supported", format))
+}
+
+func (this *PatternMetadata) authorAndYear() string {
+	if this.Year == 0 {
+		return this.Author
+	}
+
+	if this.Author == "" {
+		return fmt.Sprintf("%d", this.Year)
+	}
+
+	return fmt.Sprintf("%s, %d", this.Author, this.Year)
+}
+
+func PatternToPlaintext(pattern Pattern) string {
+	var output string
+
+	if pattern.Name != "" {
+		output += "!Name: " + pattern.Name + "\n"
+	}
+
+	if pattern.Author != "" {
+		output += "!Author: " + pattern.Author + "\n"
+	}
+
+	if pattern.Year != 0 {
+		output += fmt.Sprintf("!Year: %d\n", pattern.Year)
+	}
+
+	if pattern.Rule != "" {
+		output += "!Rule: " + pattern.Rule + "\n"
+	}
+
+	for _, line := range pattern.Description {
+		output += "!" + line + "\n"
+	}
+
+	for _, row := range pattern.Specie {
+		for _, cell := range row {
+			if cell == 0 {
+				output += "."
+				continue
+			}
+
+			output += "O"
+		}
+
+		output += "\n"
+	}
+
+	return output
+}
+
+// Coordinates are relative to the top left corner of the pattern
+func PatternToLife106(pattern Pattern) string {
+	output := "#Life 1.06\n"
+
+	for _, line := range pattern.Description {
+		output += "#D " + line + "\n"
+	}
+
+	for y, row := range pattern.Specie {
+		for x, cell := range row {
+			if cell != 0 {
+				output += fmt.Sprintf("%d %d\n", x, y)
+			}
+		}
+	}
+
+	return output
+}
+
+func PatternToRLE(pattern Pattern) string {
+	var output string
+
+	if pattern.Name != "" {
+		output += "#N " + pattern.Name + "\n"
+	}
+
+	if author := pattern.authorAndYear(); author != "" {
+		output += "#O " + author + "\n"
+	}
+
+	for _, line := range pattern.Description {
+		output += "#C " + line + "\n"
+	}
+
+	rule := pattern.Rule
+
+	if rule == "" {
+		rule = DefaultRule
+	}
+
+	h, w := pattern.Specie.Size()
+
+	output += fmt.Sprintf("x = %d, y = %d, rule = %s\n", w, h, rule)
+
+	items := make([]string, 0)
+
+	item := func(count int, tag byte) string {
+		if count == 1 {
+			return string(tag)
+		}
+
+		return fmt.Sprintf("%d%c", count, tag)
+	}
+
+	// Pending end of lines are only written before the next live cell,
+	// so empty rows at the bottom disappear
+	pendingRows := 0
+
+	for _, row := range pattern.Specie {
+		// Dead cells at the end of the row are implicit
+		length := len(row)
+
+		for length > 0 && row[length-1] == 0 {
+			length--
+		}
+
+		for x := 0; x < length; {
+			if pendingRows > 0 {
+				items = append(items, item(pendingRows, '$'))
+				pendingRows = 0
+			}
+
+			run := 1
+
+			for x+run < length && row[x+run] == row[x] {
+				run++
+			}
+
+			tag := byte('b')
+
+			if row[x] != 0 {
+				tag = 'o'
+			}
+
+			items = append(items, item(run, tag))
+
+			x += run
+		}
+
+		pendingRows++
+	}
+
+	items = append(items, "!")
+
+	line := ""
+
+	for _, i := range items {
+		if len(line)+len(i) > rleLineLength {
+			output += line + "\n"
+			line = ""
+		}
+
+		line += i
+	}
+
+	return output + line + "\n"
+}
@@ -0,0 +1,118 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type PatternFormat string
+
+const (
+	PlaintextFormat PatternFormat = "plaintext"
+	Life105Format   PatternFormat = "life105"
+	Life106Format   PatternFormat = "life106"
+	RLEFormat       PatternFormat = "rle"
+	MacrocellFormat PatternFormat = "macrocell"
+)
+
+var patternFormats = []PatternFormat{
+	PlaintextFormat,
+	Life105Format,
+	Life106Format,
+	RLEFormat,
+	MacrocellFormat,
+}
+
+func ParsePatternFormat(name string) (PatternFormat, error) {
+	for _, format := range patternFormats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	return "", errors.New(fmt.Sprintf("Unknown pattern format \"%s\"", name))
+}
+
+func isRLEHeader(line string) bool {
+	keyValue := strings.SplitN(line, "=", 2)
+	return len(keyValue) == 2 && strings.TrimSpace(keyValue[0]) == "x"
+}
+
+func isPlaintextRow(line string) bool {
+	return strings.Trim(line, ".*O") == ""
+}
+
+// .lif and .life files can be either Life 1.05 or 1.06, so they do not count
+func formatFromExtension(filename string) (PatternFormat, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".rle":
+		return RLEFormat, true
+	case ".mc":
+		return MacrocellFormat, true
+	case ".cells":
+		return PlaintextFormat, true
+	}
+
+	return "", false
+}
+
+// Headers are trusted over the file extension, which is only used when
+// the content alone is not enough to decide
+func DetectPatternFormat(filename, content string) (PatternFormat, error) {
+	lines := strings.Split(content, "\n")
+	firstLine := strings.TrimSpace(lines[0])
+
+	switch {
+	case strings.HasPrefix(firstLine, "#Life 1.05"):
+		return Life105Format, nil
+	case strings.HasPrefix(firstLine, "#Life 1.06"):
+		return Life106Format, nil
+	case strings.HasPrefix(firstLine, "[M2]"):
+		return MacrocellFormat, nil
+	case strings.HasPrefix(firstLine, "!"):
+		return PlaintextFormat, nil
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+
+		if isRLEHeader(trimmed) {
+			return RLEFormat, nil
+		}
+
+		break
+	}
+
+	if format, found := formatFromExtension(filename); found {
+		return format, nil
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+
+		if !isPlaintextRow(trimmed) {
+			return "", errors.New(fmt.Sprintf("Could not detect the format of \"%s\", please give it explicitly", filename))
+		}
+	}
+
+	return PlaintextFormat, nil
+}
+
+// Used when saving patterns, as there is no content to look at
+func PatternFormatFromFilename(filename string) (PatternFormat, error) {
+	if format, found := formatFromExtension(filename); found {
+		return format, nil
+	}
+
+	return "", errors.New(fmt.Sprintf("Could not guess the format of \"%s\" from its extension, please give it explicitly", filename))
+}
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
 	"log"
+	"math/rand"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -555,4 +559,2576 @@ func TestGameOfLife(t *testing.T) {
 
 		})
 	})
+
+	Convey("Test Import RLE files", t, func() {
+		importer := NewSpecieImporter()
+
+		glider, _ := NewSpecie([][]int{
+			{0, 1, 0},
+			{0, 0, 1},
+			{1, 1, 1},
+		})
+
+		Convey("glider", func() {
+			content := `#N Glider
+#C The smallest spaceship.
+x = 3, y = 3, rule = B3/S23
+bob$2bo$3o!`
+
+			pattern, err := importer.ImportFromRLEString(content)
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, glider)
+		})
+
+		Convey("Body spanning several lines, with trailing dead cells omitted", func() {
+			content := "x = 3, y = 3\nb\no$\n2bo\n$3o!\nanything after the end is ignored"
+
+			pattern, err := importer.ImportFromRLEString(content)
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, glider)
+		})
+
+		Convey("Runs of empty rows", func() {
+			pattern, err := importer.ImportFromRLEString("x = 2, y = 4\n2o3$bo!")
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{1, 1}, {0, 0}, {0, 0}, {0, 1}})
+		})
+
+		Convey("Missing header", func() {
+			_, err := importer.ImportFromRLEString("#C nothing here\n")
+			So(err, ShouldResemble, errors.New("Missing RLE header"))
+		})
+
+		Convey("Invalid header", func() {
+			_, err := importer.ImportFromRLEString("x = 3, y = a\nbob$2bo$3o!")
+			So(err, ShouldResemble, errors.New("Invalid value \"a\" for \"y\" on line 1, column 7"))
+		})
+
+		Convey("Row wider than the header", func() {
+			_, err := importer.ImportFromRLEString("#N Glider\nx = 3, y = 3\nbob$2bo$4o!")
+			So(err, ShouldResemble, errors.New("Row 3 is wider than the declared width 3 on line 3, column 10"))
+		})
+
+		Convey("Pattern taller than the header", func() {
+			_, err := importer.ImportFromRLEString("x = 3, y = 2\nbob$2bo$\n3o!")
+			So(err, ShouldResemble, errors.New("Pattern is taller than the declared height 2 on line 3, column 2"))
+		})
+
+		Convey("Invalid char", func() {
+			_, err := importer.ImportFromRLEString("x = 3, y = 3\nbob$2bo\n$3x!")
+			So(err, ShouldResemble, errors.New("Invalid char \"x\" on line 3, column 3"))
+		})
+
+		Convey("Missing end of pattern", func() {
+			_, err := importer.ImportFromRLEString("x = 3, y = 3\nbob$2bo$3o")
+			So(err, ShouldResemble, errors.New("Missing \"!\" at the end of the pattern on line 2, column 11"))
+		})
+	})
+
+	Convey("Test Import Life 1.06 files", t, func() {
+		importer := NewSpecieImporter()
+
+		Convey("glider with negative coordinates", func() {
+			content := `#Life 1.06
+0 -1
+1 0
+-1 1
+0 1
+1 1`
+			pattern, err := importer.ImportFromLife106String(content)
+
+			So(err, ShouldEqual, nil)
+
+			glider, _ := NewSpecie([][]int{
+				{0, 1, 0},
+				{0, 0, 1},
+				{1, 1, 1},
+			})
+
+			So(pattern.Specie, ShouldResemble, glider)
+		})
+
+		Convey("Cells far away from the origin are moved into the bounding box", func() {
+			pattern, err := importer.ImportFromLife106String("#Life 1.06\n100 50\n102 50\n")
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{1, 0, 1}})
+		})
+
+		Convey("Missing header", func() {
+			_, err := importer.ImportFromLife106String("0 0\n")
+			So(err, ShouldResemble, errors.New("Missing \"#Life 1.06\" header"))
+		})
+
+		Convey("Invalid coordinate", func() {
+			_, err := importer.ImportFromLife106String("#Life 1.06\n0 0\n1 1\n1 x\n")
+			So(err, ShouldResemble, errors.New("Invalid coordinate \"x\" on line 4, column 3"))
+		})
+
+		Convey("Incomplete pair", func() {
+			_, err := importer.ImportFromLife106String("#Life 1.06\n0\n")
+			So(err, ShouldResemble, errors.New("Expected a \"x y\" pair but found \"0\" on line 2, column 1"))
+		})
+
+		Convey("No cells", func() {
+			_, err := importer.ImportFromLife106String("#Life 1.06\n")
+			So(err, ShouldResemble, errors.New("Invalid specie"))
+		})
+	})
+
+	Convey("Test Import Life 1.05 files with several blocks", t, func() {
+		importer := NewSpecieImporter()
+
+		Convey("glider split in two blocks", func() {
+			content := `#Life 1.05
+#D This is a glider
+#D in two blocks.
+#R 23/36
+#P -1 -1
+.*
+#P -1 0
+..*
+***`
+			pattern, err := importer.ImportFromLife105String(content)
+
+			So(err, ShouldEqual, nil)
+
+			glider, _ := NewSpecie([][]int{
+				{0, 1, 0},
+				{0, 0, 1},
+				{1, 1, 1},
+			})
+
+			So(pattern.Specie, ShouldResemble, glider)
+			So(pattern.Description, ShouldResemble, []string{"This is a glider", "in two blocks."})
+			So(pattern.Rule, ShouldEqual, "B36/S23")
+		})
+
+		Convey("Blocks apart from each other", func() {
+			content := "#Life 1.05\n#N\n#P 0 0\n**\n**\n#P 3 -1\n*\n"
+			pattern, err := importer.ImportFromLife105String(content)
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{0, 0, 0, 1}, {1, 1, 0, 0}, {1, 1, 0, 0}})
+			So(pattern.Rule, ShouldEqual, "B3/S23")
+		})
+
+		Convey("Invalid block position", func() {
+			_, err := importer.ImportFromLife105String("#Life 1.05\n#P 0\n*\n")
+			So(err, ShouldResemble, errors.New("Expected \"#P x y\" but found \"#P 0\" on line 2, column 1"))
+		})
+
+		Convey("Invalid rule", func() {
+			_, err := importer.ImportFromLife105String("#Life 1.05\n#R B3/S23\n#P 0 0\n*\n")
+			So(err, ShouldResemble, errors.New("Invalid rule \"B3/S23\" on line 2, column 1"))
+		})
+
+		Convey("Invalid char", func() {
+			_, err := importer.ImportFromLife105String("#Life 1.05\n#P 0 0\n.*\n.x\n")
+			So(err, ShouldResemble, errors.New("Invalid char \"x\" on line 4, column 2"))
+		})
+	})
+
+	Convey("Detect pattern formats", t, func() {
+		Convey("From the headers", func() {
+			for content, expected := range map[string]PatternFormat{
+				"#Life 1.05\n#P 0 0\n*\n":       Life105Format,
+				"#Life 1.06\n0 0\n":             Life106Format,
+				"[M2] (golly 2.0)\n#R B3/S23\n": MacrocellFormat,
+				"!Name: Glider\n.O\n..O\nOOO\n": PlaintextFormat,
+				"#N Glider\nx = 3, y = 3\n3o!":  RLEFormat,
+			} {
+				format, err := DetectPatternFormat("pattern.lif", content)
+				So(err, ShouldEqual, nil)
+				So(format, ShouldEqual, expected)
+			}
+		})
+
+		Convey("From the extension", func() {
+			format, err := DetectPatternFormat("glider.rle", "bo$2bo$3o!")
+			So(err, ShouldEqual, nil)
+			So(format, ShouldEqual, RLEFormat)
+		})
+
+		Convey("Plain grid without any header", func() {
+			format, err := DetectPatternFormat("glider.txt", ".*.\n..*\n***\n")
+			So(err, ShouldEqual, nil)
+			So(format, ShouldEqual, PlaintextFormat)
+		})
+
+		Convey("Ambiguous content", func() {
+			_, err := DetectPatternFormat("glider.lif", "0 0\n1 1\n")
+			So(err, ShouldResemble, errors.New("Could not detect the format of \"glider.lif\", please give it explicitly"))
+		})
+
+		Convey("Format names", func() {
+			format, err := ParsePatternFormat("life106")
+			So(err, ShouldEqual, nil)
+			So(format, ShouldEqual, Life106Format)
+
+			_, err = ParsePatternFormat("png")
+			So(err, ShouldResemble, errors.New("Unknown pattern format \"png\""))
+		})
+
+		Convey("Import dispatches on the format", func() {
+			importer := NewSpecieImporter()
+
+			pattern, err := importer.Import(PlaintextFormat, "!Name: Blinker\nOOO\n")
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{1, 1, 1}})
+
+			pattern, err = importer.Import(RLEFormat, "x = 3, y = 1\n3o!")
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{1, 1, 1}})
+
+			pattern, err = importer.Import(MacrocellFormat, "[M2]\n***$\n")
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{1, 1, 1}})
+
+			_, err = importer.Import(PatternFormat("png"), "")
+			So(err, ShouldResemble, errors.New("Importing png patterns is not supported"))
+		})
+	})
+
+	Convey("Pattern metadata", t, func() {
+		importer := NewSpecieImporter()
+
+		Convey("From RLE comments and header", func() {
+			content := `#N Gosper glider gun
+#O Bill Gosper, 1970
+#C The first known gun.
+x = 3, y = 1, rule = B36/S23
+3o!`
+			pattern, err := importer.ImportFromRLEString(content)
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Name, ShouldEqual, "Gosper glider gun")
+			So(pattern.Author, ShouldEqual, "Bill Gosper")
+			So(pattern.Year, ShouldEqual, 1970)
+			So(pattern.Description, ShouldResemble, []string{"The first known gun."})
+			So(pattern.Rule, ShouldEqual, "B36/S23")
+		})
+
+		Convey("From plaintext comments", func() {
+			content := "!Name: Glider\n!Author: Richard K. Guy\n!Year: 1969\n!The smallest spaceship.\n.O\n..O\nOOO\n"
+			pattern, err := importer.ImportFromPlaintextString(content)
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Name, ShouldEqual, "Glider")
+			So(pattern.Author, ShouldEqual, "Richard K. Guy")
+			So(pattern.Year, ShouldEqual, 1969)
+			So(pattern.Description, ShouldResemble, []string{"The smallest spaceship."})
+			So(pattern.Rule, ShouldEqual, "")
+		})
+
+		Convey("From Life 1.05 descriptions", func() {
+			content := "#Life 1.05\n#D Name: Big glider\n#D Author: Dean Hickerson\n#N\n#P 0 0\n*\n"
+			pattern, err := importer.ImportFromLife105String(content)
+
+			So(err, ShouldEqual, nil)
+			So(pattern.Name, ShouldEqual, "Big glider")
+			So(pattern.Author, ShouldEqual, "Dean Hickerson")
+			So(pattern.Description, ShouldResemble, []string{})
+		})
+
+		Convey("Rules the pattern runs under", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			for rule, expected := range map[string]bool{
+				"":         true,
+				"B3/S23":   true,
+				"b3/s23":   true,
+				"23/3":     true,
+				"S23/B3":   true,
+				"B36/S23":  false,
+				"B3/S23V":  false,
+				"nonsense": false,
+			} {
+				metadata := PatternMetadata{Rule: rule}
+				So(metadata.RunsUnderRule(conway), ShouldEqual, expected)
+			}
+		})
+
+		Convey("Species in the config file", func() {
+			jsonContent := `{"Species": {
+				"blinker": [[1,1,1]],
+				"replicator": {"Specie": [[0,1,1,1],[1,0,0,1]], "Name": "Replicator", "Rule": "B36/S23"}
+			}}`
+
+			config, err := ParseConfig(jsonContent)
+
+			So(err, ShouldEqual, nil)
+			So(config.Species["blinker"].Specie, ShouldResemble, Specie{{1, 1, 1}})
+			So(config.Species["blinker"].Name, ShouldEqual, "")
+			So(config.Species["replicator"].Specie, ShouldResemble, Specie{{0, 1, 1, 1}, {1, 0, 0, 1}})
+			So(config.Species["replicator"].Name, ShouldEqual, "Replicator")
+			So(config.Species["replicator"].Rule, ShouldEqual, "B36/S23")
+		})
+
+		Convey("Species without cells in the config file", func() {
+			_, err := ParseConfig(`{"Species": {"nothing": {"Name": "Nothing"}}}`)
+			So(err, ShouldResemble, errors.New("Invalid specie"))
+		})
+	})
+
+	Convey("Export the world", t, func() {
+		world, _ := NewWorld(10, 10)
+		placer := NewLifePlacer(&world)
+
+		glider, _ := NewSpecie([][]int{
+			{0, 1, 0},
+			{0, 0, 1},
+			{1, 1, 1},
+		})
+
+		placer.Place(glider, NewCoord(4, 2))
+
+		exporter := NewExporter(&world)
+		importer := NewSpecieImporter()
+
+		Convey("Capture the live cells", func() {
+			pattern, err := exporter.Capture()
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, glider)
+		})
+
+		Convey("Capture a region", func() {
+			pattern, err := exporter.CaptureRegion(NewCoord(3, 2), 2, 4)
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, Specie{{0, 0, 1, 0}, {0, 0, 0, 1}})
+		})
+
+		Convey("Region out of the world", func() {
+			_, err := exporter.CaptureRegion(NewCoord(8, 8), 3, 3)
+			So(err, ShouldResemble, errors.New("Invalid region"))
+		})
+
+		Convey("Empty world", func() {
+			empty, _ := NewWorld(3, 3)
+			emptyExporter := NewExporter(&empty)
+			_, err := emptyExporter.Export(RLEFormat)
+			So(err, ShouldResemble, errors.New("The world is empty"))
+		})
+
+		Convey("RLE", func() {
+			content, err := exporter.Export(RLEFormat)
+			So(err, ShouldEqual, nil)
+			So(content, ShouldEqual, "x = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n")
+		})
+
+		Convey("Life 1.06", func() {
+			content, err := exporter.Export(Life106Format)
+			So(err, ShouldEqual, nil)
+			So(content, ShouldEqual, "#Life 1.06\n1 0\n2 1\n0 2\n1 2\n2 2\n")
+		})
+
+		Convey("Plaintext", func() {
+			content, err := exporter.Export(PlaintextFormat)
+			So(err, ShouldEqual, nil)
+			So(content, ShouldEqual, ".O.\n..O\nOOO\n")
+		})
+
+		Convey("Unsupported format", func() {
+			_, err := exporter.Export(Life105Format)
+			So(err, ShouldResemble, errors.New("Exporting life105 patterns is not supported"))
+		})
+
+		Convey("Region with empty rows and columns", func() {
+			content, err := exporter.ExportRegion(RLEFormat, NewCoord(0, 0), 6, 8)
+			So(err, ShouldEqual, nil)
+			So(content, ShouldEqual, "x = 8, y = 6, rule = B3/S23\n2$5bo$6bo$4b3o!\n")
+		})
+
+		Convey("Round trip through the importer", func() {
+			original := NewPattern(Specie{
+				{0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0},
+				{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0},
+				{1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1},
+			})
+
+			original.Name = "Noise"
+			original.Author = "Someone"
+			original.Year = 2016
+			original.Description = []string{"Long enough to wrap the lines."}
+			original.Rule = "B36/S23"
+
+			for _, format := range []PatternFormat{RLEFormat, PlaintextFormat} {
+				content, err := ExportPattern(format, original)
+				So(err, ShouldEqual, nil)
+
+				for _, line := range strings.Split(content, "\n") {
+					So(len(line), ShouldBeLessThanOrEqualTo, 70)
+				}
+
+				pattern, err := importer.Import(format, content)
+				So(err, ShouldEqual, nil)
+				So(pattern, ShouldResemble, original)
+			}
+
+			content, err := ExportPattern(Life106Format, original)
+			So(err, ShouldEqual, nil)
+
+			pattern, err := importer.Import(Life106Format, content)
+			So(err, ShouldEqual, nil)
+			So(pattern.Specie, ShouldResemble, original.Specie)
+			So(pattern.Description, ShouldResemble, original.Description)
+		})
+	})
+
+	Convey("Format from the file name", t, func() {
+		format, err := PatternFormatFromFilename("world.cells")
+		So(err, ShouldEqual, nil)
+		So(format, ShouldEqual, PlaintextFormat)
+
+		_, err = PatternFormatFromFilename("world.lif")
+		So(err, ShouldResemble, errors.New("Could not guess the format of \"world.lif\" from its extension, please give it explicitly"))
+	})
+
+	Convey("Life-like rule strings", t, func() {
+		Convey("Different notations for the same rule", func() {
+			for rule, expected := range map[string]string{
+				"B3/S23":      "B3/S23",
+				"b36/s23":     "B36/S23",
+				"S23/B36":     "B36/S23",
+				"B3678S34678": "B3678/S34678",
+				"23/36":       "B36/S23",
+				"/2":          "B2/S",
+				"B2/S":        "B2/S",
+				"B368/S245":   "B368/S245",
+				"B2/S1V":      "B2/S1V",
+				"1/2v":        "B2/S1V",
+			} {
+				rule, err := ParseLifeLikeRule(rule)
+				So(err, ShouldEqual, nil)
+				So(rule.String(), ShouldEqual, expected)
+			}
+		})
+
+		Convey("Invalid rules", func() {
+			for _, rule := range []string{"B9/S23", "B3/S5V", "B3", "3", "B3/S2x", "B3/B3", ""} {
+				_, err := ParseLifeLikeRule(rule)
+				So(err, ShouldResemble, errors.New("Invalid rule \""+rule+"\""))
+			}
+		})
+
+		Convey("Birth on 0 neighbours", func() {
+			_, err := ParseLifeLikeRule("B0/S8")
+			So(err, ShouldResemble, errors.New("Rules with birth on 0 neighbours, as \"B0/S8\", are not supported"))
+		})
+
+		Convey("HighLife is born with 6 neighbours", func() {
+			for rule, expected := range map[string]bool{"B3/S23": false, "B36/S23": true} {
+				world, _ := NewWorld(3, 3)
+
+				for _, c := range []Coord{{0, 0}, {1, 0}, {2, 0}, {0, 2}, {1, 2}, {2, 2}} {
+					world.ActivateCell(c)
+				}
+
+				rules, err := CreateRulesFromString(&world, rule)
+				So(err, ShouldEqual, nil)
+
+				generator := NewGenericGenerator(&world, rules)
+				generator.Step()
+
+				live, _ := world.IsCellLive(NewCoord(1, 1))
+				So(live, ShouldEqual, expected)
+			}
+		})
+
+		Convey("Von Neumann neighbourhood ignores diagonals", func() {
+			world, _ := NewWorld(3, 3)
+			world.ActivateCell(NewCoord(1, 1))
+
+			rules, err := CreateRulesFromString(&world, "B1/SV")
+			So(err, ShouldEqual, nil)
+
+			generator := NewGenericGenerator(&world, rules)
+			generator.Step()
+
+			for y := 0; y < 3; y++ {
+				for x := 0; x < 3; x++ {
+					live, _ := world.IsCellLive(NewCoord(x, y))
+					So(live, ShouldEqual, (x == 1) != (y == 1))
+				}
+			}
+		})
+
+		Convey("Rule in the config file", func() {
+			config, err := ParseConfig(`{"Rule": "B36/S23"}`)
+			So(err, ShouldEqual, nil)
+			So(config.Rule, ShouldEqual, "B36/S23")
+		})
+	})
+
+	Convey("Isotropic non-totalistic rules", t, func() {
+		Convey("Neighbourhood configuration", func() {
+			world, _ := NewWorld(3, 3)
+			world.ActivateCell(NewCoord(0, 0))
+			world.ActivateCell(NewCoord(2, 1))
+
+			So(world.GetCellNeighbourhoodConfiguration(NewCoord(1, 1)), ShouldEqual, 0x90)
+			So(world.GetCellNeighbourhoodConfiguration(NewCoord(0, 1)), ShouldEqual, 0x40)
+		})
+
+		Convey("Canonical rule strings", func() {
+			for rule, expected := range map[string]string{
+				"B2-a/S12":               "B2-a/S12",
+				"b2cekin/s12":            "B2-a/S12",
+				"B3cekainyqjr/S2cekain3": "B3/S23",
+				"B2i34cj/S2-i3":          "B2i34cj/S2-i3",
+				"B3-cnqy/S23-a4ik":       "B3-cnyq/S23-a4ki",
+				"B2n3/S23-q":             "B2n3/S23-q",
+				"B2ce3aiy/S2-k3":         "B2ce3aiy/S2-k3",
+			} {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+				So(ruleset.String(), ShouldEqual, expected)
+			}
+		})
+
+		Convey("Totalistic rules written with letters are the same rule", func() {
+			conway, _ := ParseRule(DefaultRule)
+			metadata := PatternMetadata{Rule: "B3cekainyqjr/S2cekain3cekainyqjr"}
+			So(metadata.RunsUnderRule(conway), ShouldBeTrue)
+		})
+
+		Convey("Invalid rules", func() {
+			for _, rule := range []string{"B2x/S", "B2-/S", "B5t/S", "B2a", "B9c/S"} {
+				_, err := ParseRule(rule)
+				So(err, ShouldResemble, errors.New("Invalid rule \""+rule+"\""))
+			}
+		})
+
+		Convey("Birth depends on the shape of the neighbours", func() {
+			step := func(cells ...Coord) bool {
+				world, _ := NewWorld(3, 3)
+
+				for _, c := range cells {
+					world.ActivateCell(c)
+				}
+
+				rules, err := CreateRulesFromString(&world, "B2-a/S12")
+				So(err, ShouldEqual, nil)
+
+				generator := NewGenericGenerator(&world, rules)
+				generator.Step()
+
+				live, _ := world.IsCellLive(NewCoord(1, 1))
+				return live
+			}
+
+			// 2a, adjacent neighbours
+			So(step(NewCoord(0, 0), NewCoord(1, 0)), ShouldBeFalse)
+			So(step(NewCoord(2, 1), NewCoord(2, 2)), ShouldBeFalse)
+
+			// 2i, opposite edges
+			So(step(NewCoord(1, 0), NewCoord(1, 2)), ShouldBeTrue)
+
+			// 2n, opposite corners
+			So(step(NewCoord(0, 2), NewCoord(2, 0)), ShouldBeTrue)
+		})
+	})
+
+	Convey("Generations rules", t, func() {
+		Convey("Cells decay through the dying states", func() {
+			cell := NewLiveCell()
+			So(cell.IsDying(), ShouldBeFalse)
+
+			cell = cell.Decay(4)
+			So(cell.IsLive(), ShouldBeFalse)
+			So(cell.IsDying(), ShouldBeTrue)
+			So(cell, ShouldEqual, Cell(2))
+
+			cell = cell.Decay(4)
+			So(cell, ShouldEqual, Cell(3))
+
+			cell = cell.Decay(4)
+			So(cell, ShouldEqual, NewDeadCell())
+		})
+
+		Convey("Rule strings", func() {
+			for rule, expected := range map[string]string{
+				"/2/3":        "B2/S/C3",
+				"B2/S/C3":     "B2/S/C3",
+				"345/2/4":     "B2/S345/C4",
+				"B2/S345/G4":  "B2/S345/C4",
+				"B3/S23/C2":   "B3/S23",
+				"B2-a/S12/C5": "B2-a/S12/C5",
+			} {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+				So(ruleset.String(), ShouldEqual, expected)
+			}
+		})
+
+		Convey("Invalid number of states", func() {
+			for _, rule := range []string{"B2/S/C1", "B2/S/Cx", "B2/S/C257"} {
+				_, err := ParseRule(rule)
+				So(err, ShouldResemble, errors.New("Invalid number of states in rule \""+rule+"\""))
+			}
+
+			_, err := ParseRule("B2/X/C3")
+			So(err, ShouldResemble, errors.New("Invalid rule \"B2/X/C3\""))
+		})
+
+		Convey("Brian's Brain", func() {
+			world, _ := NewWorld(3, 4)
+			world.ActivateCell(NewCoord(1, 1))
+			world.ActivateCell(NewCoord(2, 1))
+
+			ruleset, _ := ParseRule("/2/3")
+			So(ruleset.States(), ShouldEqual, 3)
+
+			generator := NewRulesetGenerator(&world, ruleset)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+
+			So(printer.Print(), ShouldEqual, "######\n# oo #\n# ** #\n# oo #\n######\n")
+
+			generator.Step()
+
+			// Dying cells do not count as neighbours and cannot be born again before dying
+			So(printer.Print(), ShouldEqual, "######\n# ** #\n#o  o#\n# ** #\n######\n")
+
+			cell, err := world.GetCell(NewCoord(1, 0))
+			So(err, ShouldEqual, nil)
+			So(cell, ShouldEqual, Cell(2))
+		})
+
+		Convey("Glyphs of the dying states", func() {
+			So(CellGlyph(Cell(0)), ShouldEqual, " ")
+			So(CellGlyph(Cell(1)), ShouldEqual, "o")
+			So(CellGlyph(Cell(2)), ShouldEqual, "*")
+			So(CellGlyph(Cell(6)), ShouldEqual, ":")
+			So(CellGlyph(Cell(200)), ShouldEqual, ".")
+		})
+	})
+
+	Convey("Larger than Life", t, func() {
+		Convey("Neighbourhood sizes", func() {
+			for neighbourhoodType, size := range map[NeighbourhoodType]int{
+				MooreNeighbourhood:        24,
+				VonNeumannNeighbourhood:   12,
+				CircularNeighbourhood:     20,
+				CrossNeighbourhood:        8,
+				CheckerboardNeighbourhood: 12,
+			} {
+				neighbourhood, err := NewNeighbourhood(neighbourhoodType, 2)
+				So(err, ShouldEqual, nil)
+				So(neighbourhood.Size(), ShouldEqual, size)
+			}
+
+			_, err := NewNeighbourhood("X", 2)
+			So(err, ShouldResemble, errors.New("Unknown neighbourhood \"X\""))
+
+			_, err = NewNeighbourhood(MooreNeighbourhood, 0)
+			So(err, ShouldResemble, errors.New("Invalid neighbourhood range 0"))
+		})
+
+		Convey("Neighbours wrap around circular worlds", func() {
+			world, _ := NewCircularWorld(5, 5)
+			world.SetNeighbourhood(Neighbourhood{Type: CrossNeighbourhood, Range: 2})
+
+			So(world.GetCellNeighboursCoords(NewCoord(0, 0)), ShouldResemble, NeighboursCoords{
+				NewCoord(0, 3), NewCoord(0, 4), NewCoord(3, 0), NewCoord(4, 0),
+				NewCoord(1, 0), NewCoord(2, 0), NewCoord(0, 1), NewCoord(0, 2),
+			})
+		})
+
+		Convey("Rule strings", func() {
+			for rule, expected := range map[string]string{
+				"R5,C0,M1,S34..58,B34..45,NM":  "R5,C0,M1,S34..58,B34..45,NM",
+				"r2,c2,s2..3,5..6,b3":          "R2,C0,M0,S2..3,5..6,B3..3,NM",
+				"R3,C4,M0,S2..4,B3..4,NN":      "R3,C4,M0,S2..4,B3..4,NN",
+				"R2,C0,M0,S1..5,B1..2,N+":      "R2,C0,M0,S1..5,B1..2,N+",
+				"R1, C0, M0, S2..3, B3..3, NC": "R1,C0,M0,S2..3,B3..3,NC",
+			} {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+				So(ruleset.String(), ShouldEqual, expected)
+			}
+
+			bugs, _ := ParseRule("R5,C0,M1,S34..58,B34..45,NM")
+			So(bugs.States(), ShouldEqual, 2)
+			So(bugs.GetNeighbourhood(), ShouldResemble, Neighbourhood{Type: MooreNeighbourhood, Range: 5})
+		})
+
+		Convey("Invalid rule strings", func() {
+			for _, rule := range []string{
+				"R0,C0,M0,S2..3,B3..3,NM",
+				"R2,C0,M0,S2..3,NM",
+				"R2,C0,M2,S2..3,B3..3,NM",
+				"R2,C0,M0,S3..2,B3..3,NM",
+				"R1,C0,M0,S2..9,B3..3,NM",
+				"R2,C0,M0,S2..3,B3..3,X",
+				"R2,,S2..3,B3..3",
+			} {
+				_, err := ParseRule(rule)
+				So(err, ShouldResemble, errors.New("Invalid rule \""+rule+"\""))
+			}
+
+			_, err := ParseRule("R2,C0,M0,S2..3,B3..3,NX")
+			So(err, ShouldResemble, errors.New("Unknown neighbourhood \"X\""))
+
+			_, err = ParseRule("R2,C1,M0,S2..3,B3..3,NM")
+			So(err, ShouldResemble, errors.New("Invalid number of states in rule \"R2,C1,M0,S2..3,B3..3,NM\""))
+
+			_, err = ParseRule("R2,C0,M0,S2..3,B0..3,NM")
+			So(err, ShouldResemble, errors.New("Rules with birth on 0 neighbours, as \"R2,C0,M0,S2..3,B0..3,NM\", are not supported"))
+		})
+
+		Convey("Range 1 Moore is Conway's game of life", func() {
+			world, _ := NewWorld(3, 3)
+			world.ActivateCell(NewCoord(0, 1))
+			world.ActivateCell(NewCoord(1, 1))
+			world.ActivateCell(NewCoord(2, 1))
+
+			ruleset, _ := ParseRule("R1,C0,M0,S2..3,B3..3,NM")
+			generator := NewRulesetGenerator(&world, ruleset)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "#####\n# o #\n# o #\n# o #\n#####\n")
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "#####\n#   #\n#ooo#\n#   #\n#####\n")
+		})
+
+		Convey("Cells are born two cells away", func() {
+			world, _ := NewWorld(7, 7)
+
+			// Placed before the neighbourhood is known
+			world.ActivateCell(NewCoord(3, 3))
+
+			ruleset, _ := ParseRule("R2,C0,M0,S1..24,B1..1,NM")
+			generator := NewRulesetGenerator(&world, ruleset)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+
+			So(printer.Print(), ShouldEqual, "#########\n"+
+				"#       #\n"+
+				"# ooooo #\n"+
+				"# ooooo #\n"+
+				"# oo oo #\n"+
+				"# ooooo #\n"+
+				"# ooooo #\n"+
+				"#       #\n"+
+				"#########\n")
+		})
+	})
+
+	Convey("Custom neighbourhoods", t, func() {
+		Convey("Invalid masks", func() {
+			_, err := NewNeighbourhoodFromMask([][]int{})
+			So(err, ShouldResemble, errors.New("The neighbourhood mask must have an odd number of rows"))
+
+			_, err = NewNeighbourhoodFromMask([][]int{{1, 1}})
+			So(err, ShouldResemble, errors.New("The neighbourhood mask must have an odd number of columns"))
+
+			_, err = NewNeighbourhoodFromMask([][]int{{1, 1, 1}, {1, 0, 1}, {1, 1}})
+			So(err, ShouldResemble, errors.New("Row 3 of the neighbourhood mask has 2 columns instead of 3"))
+
+			_, err = NewNeighbourhoodFromMask([][]int{{1, -1, 1}})
+			So(err, ShouldResemble, errors.New("Neighbourhood weights cannot be negative"))
+		})
+
+		Convey("Offsets and weights", func() {
+			neighbourhood, err := NewNeighbourhoodFromMask([][]int{
+				{0, 2, 0, 0, 0},
+				{1, 0, 3, 0, 1},
+				{0, 0, 0, 0, 0},
+			})
+
+			So(err, ShouldEqual, nil)
+			So(neighbourhood.Range, ShouldEqual, 2)
+			So(neighbourhood.Offsets(), ShouldResemble, []Coord{NewCoord(-1, -1), NewCoord(-2, 0), NewCoord(2, 0)})
+			So(neighbourhood.Weight(NewCoord(-1, -1)), ShouldEqual, 2)
+			So(neighbourhood.Weight(NewCoord(1, 1)), ShouldEqual, 0)
+			So(neighbourhood.Weight(NewCoord(0, 5)), ShouldEqual, 0)
+			So(neighbourhood.MiddleWeight(), ShouldEqual, 3)
+		})
+
+		Convey("Live neighbours are weighted", func() {
+			world, _ := NewWorld(3, 3)
+			neighbourhood, _ := NewNeighbourhoodFromMask([][]int{
+				{1, 2, 1},
+				{2, 1, 2},
+				{1, 2, 1},
+			})
+			world.SetNeighbourhood(neighbourhood)
+
+			world.ActivateCell(NewCoord(0, 0))
+			world.ActivateCell(NewCoord(1, 0))
+
+			So(world.CountLiveNeighbours(NewCoord(1, 1)), ShouldEqual, 3)
+			So(world.CountLiveNeighbours(NewCoord(0, 0)), ShouldEqual, 3)
+			So(world.CountLiveNeighbours(NewCoord(2, 2)), ShouldEqual, 0)
+		})
+
+		Convey("Asymmetric neighbourhoods", func() {
+			world, _ := NewWorld(1, 4)
+			world.ActivateCell(NewCoord(0, 0))
+
+			// Only the cell on the west matters, so cells move to the east
+			ruleset, _ := ParseRule("B1/S")
+			neighbourhood, _ := NewNeighbourhoodFromMask([][]int{{1, 0, 0}})
+			ruleset, err := NewCustomNeighbourhoodRule(ruleset, neighbourhood)
+			So(err, ShouldEqual, nil)
+			So(ruleset.String(), ShouldEqual, "B1/S")
+
+			generator := NewRulesetGenerator(&world, ruleset)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "######\n# o  #\n######\n")
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "######\n#  o #\n######\n")
+		})
+
+		Convey("Weighted Larger than Life rules", func() {
+			world, _ := NewWorld(3, 3)
+			world.ActivateCell(NewCoord(1, 0))
+
+			// Born next to the orthogonal neighbours only
+			ruleset, _ := ParseRule("R1,C0,M0,S1..8,B2..2,NM")
+			neighbourhood, _ := NewNeighbourhoodFromMask([][]int{
+				{1, 2, 1},
+				{2, 0, 2},
+				{1, 2, 1},
+			})
+			ruleset, _ = NewCustomNeighbourhoodRule(ruleset, neighbourhood)
+
+			generator := NewRulesetGenerator(&world, ruleset)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "#####\n#o o#\n# o #\n#   #\n#####\n")
+		})
+
+		Convey("Life-like rules only count as many neighbours as their own neighbourhood has", func() {
+			ones := make([][]int, 5)
+
+			for i := range ones {
+				ones[i] = []int{1, 1, 1, 1, 1}
+			}
+
+			ones[2][2] = 0
+
+			neighbourhood, _ := NewNeighbourhoodFromMask(ones)
+			So(neighbourhood.TotalWeight(), ShouldEqual, 24)
+
+			for _, rule := range []string{"B3/S23", "B3/S23/C4"} {
+				ruleset, _ := ParseRule(rule)
+				_, err := NewCustomNeighbourhoodRule(ruleset, neighbourhood)
+				So(err, ShouldResemble, errors.New("Rule "+rule+" counts up to 8 live neighbours, but the weights of the mask add up to 24"))
+			}
+
+			// The triangular rules count up to 12
+			triangular, _ := ParseRule("B4/S345L")
+			twelve, _ := NewNeighbourhoodFromMask([][]int{{0, 1, 1, 1, 1}, {1, 1, 0, 1, 1}, {1, 1, 1, 1, 0}})
+			_, err := NewCustomNeighbourhoodRule(triangular, twelve)
+			So(err, ShouldEqual, nil)
+
+			// Larger than Life rules count any number of them
+			largerThanLife, _ := ParseRule("R2,C0,M0,S10..14,B10..10,NM")
+			ruleset, err := NewCustomNeighbourhoodRule(largerThanLife, neighbourhood)
+			So(err, ShouldEqual, nil)
+
+			world, _ := NewWorld(7, 7)
+
+			for y := 1; y < 6; y++ {
+				for x := 1; x < 6; x++ {
+					if x+y <= 5 {
+						world.ActivateCell(NewCoord(x, y))
+					}
+				}
+			}
+
+			generator := NewRulesetGenerator(&world, ruleset)
+
+			// Counting up to 24 neighbours, 10 of them live for the cell in the middle
+			So(world.CountLiveNeighbours(NewCoord(3, 3)), ShouldEqual, 10)
+
+			generator.Step()
+
+			So(world.ActiveMatrix.IsLive(NewCoord(3, 3)), ShouldBeTrue)
+		})
+
+		Convey("Isotropic rules need the Moore neighbourhood", func() {
+			ruleset, _ := ParseRule("B2-a/S12")
+			neighbourhood, _ := NewNeighbourhoodFromMask([][]int{{1, 0, 1}})
+			_, err := NewCustomNeighbourhoodRule(ruleset, neighbourhood)
+			So(err, ShouldResemble, errors.New("Rule B2-a/S12 does not support custom neighbourhoods"))
+		})
+
+		Convey("From the configuration", func() {
+			config, err := ParseConfig(`{"Neighbourhood": [[0,1,0],[1,0,1],[0,1,0]]}`)
+			So(err, ShouldEqual, nil)
+
+			neighbourhood, err := NewNeighbourhoodFromMask(config.Neighbourhood)
+			So(err, ShouldEqual, nil)
+			So(neighbourhood.Size(), ShouldEqual, 4)
+		})
+	})
+
+	Convey("Hexagonal grid", t, func() {
+		Convey("Rule strings", func() {
+			for rule, expected := range map[string]string{
+				"B2/S34H":   "B2/S34H",
+				"34/2H":     "B2/S34H",
+				"345/2/4H":  "B2/S345H/C4",
+				"B2/S3H/C5": "B2/S3H/C5",
+			} {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+				So(ruleset.String(), ShouldEqual, expected)
+				So(ruleset.GetNeighbourhood(), ShouldResemble, Neighbourhood{Type: HexagonalNeighbourhood, Range: 1})
+			}
+
+			_, err := ParseRule("B2/S37H")
+			So(err, ShouldResemble, errors.New("Invalid rule \"B2/S37H\""))
+
+			_, err = ParseRule("B2/S3VH")
+			So(err, ShouldResemble, errors.New("Invalid rule \"B2/S3VH\""))
+
+			ruleset, err := ParseRule("R2,C0,M0,S2..4,B3..3,NH")
+			So(err, ShouldEqual, nil)
+			So(ruleset.String(), ShouldEqual, "R2,C0,M0,S2..4,B3..3,NH")
+		})
+
+		Convey("Neighbourhoods", func() {
+			neighbourhood, _ := NewNeighbourhood(HexagonalNeighbourhood, 1)
+			So(neighbourhood.Size(), ShouldEqual, 6)
+
+			neighbourhood, _ = NewNeighbourhood(HexagonalNeighbourhood, 2)
+			So(neighbourhood.Size(), ShouldEqual, 18)
+		})
+
+		Convey("Grids", func() {
+			grid, err := ParseGrid("")
+			So(err, ShouldEqual, nil)
+			So(grid, ShouldEqual, SquareGrid)
+
+			grid, err = ParseGrid("hexagonal")
+			So(err, ShouldEqual, nil)
+			So(grid, ShouldEqual, HexagonalGrid)
+
+			_, err = ParseGrid("octogonal")
+			So(err, ShouldResemble, errors.New("Unknown grid \"octogonal\""))
+
+			conway, _ := ParseRule(DefaultRule)
+			hexagonal, _ := ParseRule("B2/S34H")
+
+			So(SquareGrid.Supports(conway), ShouldBeTrue)
+			So(SquareGrid.Supports(hexagonal), ShouldBeTrue)
+			So(HexagonalGrid.Supports(conway), ShouldBeFalse)
+			So(HexagonalGrid.Supports(hexagonal), ShouldBeTrue)
+		})
+
+		Convey("Wrapping around a circular world", func() {
+			world, _ := NewCircularWorld(4, 4)
+			world.SetGrid(HexagonalGrid)
+
+			So(world.GetCellNeighboursCoords(NewCoord(0, 0)), ShouldResemble, NeighboursCoords{
+				NewCoord(3, 3),
+				NewCoord(0, 3),
+				NewCoord(1, 0),
+				NewCoord(1, 1),
+				NewCoord(0, 1),
+				NewCoord(3, 0),
+			})
+		})
+
+		Convey("Step and print", func() {
+			world, _ := NewWorld(3, 4)
+			world.SetGrid(HexagonalGrid)
+			world.ActivateCell(NewCoord(1, 1))
+			world.ActivateCell(NewCoord(2, 1))
+
+			printer := NewPrinter(&world)
+
+			So(printer.Print(), ShouldEqual, ""+
+				"    # # # # # #\n"+
+				"   #         #\n"+
+				"  #   o o   #\n"+
+				" #         #\n"+
+				"# # # # # #\n")
+
+			ruleset, _ := ParseRule("B2/S34H")
+			generator := NewRulesetGenerator(&world, ruleset)
+
+			generator.Step()
+
+			// Only the two cells touching both are born
+			So(printer.Print(), ShouldEqual, ""+
+				"    # # # # # #\n"+
+				"   #   o     #\n"+
+				"  #         #\n"+
+				" #     o   #\n"+
+				"# # # # # #\n")
+		})
+	})
+
+	Convey("Triangular grid", t, func() {
+		Convey("Rule strings", func() {
+			for rule, expected := range map[string]string{
+				"B4/S345L": "B4/S345L",
+				"B2/S3LE":  "B2/S3LE",
+				"b2/s3lv":  "B2/S3LV",
+				"/2/3LE":   "B2/SLE/C3",
+			} {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+				So(ruleset.String(), ShouldEqual, expected)
+
+				neighbourhood := ruleset.GetNeighbourhood()
+				So(neighbourhood.IsTriangular(), ShouldBeTrue)
+			}
+
+			_, err := ParseRule("B2/S4LE")
+			So(err, ShouldResemble, errors.New("Invalid rule \"B2/S4LE\""))
+		})
+
+		Convey("Counts above 8", func() {
+			for rule, expected := range map[string]string{
+				"B4/S56789101112L": "B4/S56789101112L",
+				"B4/S9,10,12L":     "B4/S91012L",
+				"B4/S3,12L":        "B4/S312L",
+				"B4,12/S12L":       "B412/S12L",
+				"B4/S,12L":         "B4/S12,L",
+				"B4/S12,L":         "B4/S12,L",
+				"B4/S0,12L":        "B4/S0,12L",
+				"B4/S39LV":         "B4/S39LV",
+				"B4/S93LV":         "B4/S39LV",
+			} {
+				ruleset, err := ParseRule(rule)
+				So(err, ShouldEqual, nil)
+				So(ruleset.String(), ShouldEqual, expected)
+
+				// Read back as the same rule
+				again, err := ParseRule(expected)
+				So(err, ShouldEqual, nil)
+				So(again, ShouldResemble, ruleset)
+			}
+
+			for _, rule := range []string{"B4/S1313L", "B4/S9,10LV", "B4/S55L", "B4/S9,xL"} {
+				_, err := ParseRule(rule)
+				So(err, ShouldResemble, errors.New("Invalid rule \""+rule+"\""))
+			}
+		})
+
+		Convey("Cells with more than 8 live neighbours", func() {
+			// Survives only with all the 12 neighbours live
+			ruleset, _ := ParseRule("B/S12,L")
+
+			world, _ := NewWorld(6, 8)
+			world.SetGrid(TriangularGrid)
+
+			for y := 1; y < 5; y++ {
+				for x := 1; x < 7; x++ {
+					world.ActivateCell(NewCoord(x, y))
+				}
+			}
+
+			generator := NewRulesetGenerator(&world, ruleset)
+			generator.Step()
+
+			So(world.Population(), ShouldEqual, 4)
+
+			for _, coord := range []Coord{{3, 2}, {4, 2}, {3, 3}, {4, 3}} {
+				So(world.ActiveMatrix.IsLive(coord), ShouldBeTrue)
+			}
+		})
+
+		Convey("Neighbourhoods", func() {
+			for neighbourhoodType, size := range map[NeighbourhoodType]int{
+				TriangularEdgesNeighbourhood:    3,
+				TriangularVerticesNeighbourhood: 9,
+				TriangularNeighbourhood:         12,
+			} {
+				neighbourhood, err := NewNeighbourhood(neighbourhoodType, 1)
+				So(err, ShouldEqual, nil)
+				So(neighbourhood.Size(), ShouldEqual, size)
+			}
+
+			_, err := NewNeighbourhood(TriangularNeighbourhood, 2)
+			So(err, ShouldResemble, errors.New("Triangular neighbourhoods have range 1, not 2"))
+
+			edges, _ := NewNeighbourhood(TriangularEdgesNeighbourhood, 1)
+
+			So(edges.OffsetsAt(NewCoord(0, 0)), ShouldResemble, []Coord{NewCoord(-1, 0), NewCoord(1, 0), NewCoord(0, 1)})
+			So(edges.OffsetsAt(NewCoord(1, 0)), ShouldResemble, []Coord{NewCoord(-1, 0), NewCoord(1, 0), NewCoord(0, -1)})
+		})
+
+		Convey("Grid", func() {
+			grid, err := ParseGrid("triangular")
+			So(err, ShouldEqual, nil)
+			So(grid, ShouldEqual, TriangularGrid)
+
+			conway, _ := ParseRule(DefaultRule)
+			triangular, _ := ParseRule("B4/S345L")
+
+			So(TriangularGrid.Supports(conway), ShouldBeFalse)
+			So(TriangularGrid.Supports(triangular), ShouldBeTrue)
+			So(HexagonalGrid.Supports(triangular), ShouldBeFalse)
+
+			So(IsUpTriangle(NewCoord(0, 0)), ShouldBeTrue)
+			So(IsUpTriangle(NewCoord(1, 0)), ShouldBeFalse)
+			So(IsUpTriangle(NewCoord(-1, 0)), ShouldBeFalse)
+		})
+
+		Convey("Wrapping keeps the orientation", func() {
+			world, _ := NewCircularWorld(3, 4)
+			world.SetGrid(TriangularGrid)
+
+			neighbours := world.GetCellNeighboursCoords(NewCoord(0, 0))
+
+			// Wrapping an odd number of rows would turn down triangles into up ones
+			So(neighbours, ShouldContain, NewCoord(3, 0))
+			So(neighbours, ShouldContain, NewCoord(2, 1))
+			So(neighbours, ShouldNotContain, NewCoord(0, 2))
+			So(len(neighbours), ShouldEqual, 9)
+
+			world, _ = NewCircularWorld(4, 4)
+			world.SetGrid(TriangularGrid)
+
+			So(len(world.GetCellNeighboursCoords(NewCoord(0, 0))), ShouldEqual, 12)
+		})
+
+		Convey("Step and print", func() {
+			world, _ := NewWorld(3, 4)
+			world.SetGrid(TriangularGrid)
+			world.ActivateCell(NewCoord(1, 1))
+
+			printer := NewPrinter(&world)
+
+			So(printer.Print(), ShouldEqual, "######\n#/\\/\\#\n#\\A\\/#\n#/\\/\\#\n######\n")
+
+			ruleset, _ := ParseRule("B1/SLE")
+			generator := NewRulesetGenerator(&world, ruleset)
+
+			generator.Step()
+
+			So(printer.Print(), ShouldEqual, "######\n#/\\/\\#\n#V/V/#\n#/V/\\#\n######\n")
+		})
+	})
+
+	Convey("Topologies", t, func() {
+		Convey("Parsing", func() {
+			for spec, expected := range map[string]Topology{
+				"":              {Type: PlaneTopology},
+				"torus":         {Type: TorusTopology},
+				"klein":         {Type: KleinBottleTopology, TwistedHorizontalEdges: true},
+				"cross-surface": {Type: CrossSurfaceTopology},
+				"sphere":        {Type: SphereTopology},
+				"P30,20":        {Type: PlaneTopology, Width: 30, Height: 20},
+				"T100,50":       {Type: TorusTopology, Width: 100, Height: 50},
+				"T100,50+5":     {Type: TorusTopology, Width: 100, Height: 50, VerticalShift: 5},
+				"T100-3,50":     {Type: TorusTopology, Width: 100, Height: 50, HorizontalShift: -3},
+				"K30*,20":       {Type: KleinBottleTopology, Width: 30, Height: 20, TwistedHorizontalEdges: true},
+				"K30,20*":       {Type: KleinBottleTopology, Width: 30, Height: 20},
+				"C30,20":        {Type: CrossSurfaceTopology, Width: 30, Height: 20},
+				"S30":           {Type: SphereTopology, Width: 30, Height: 30},
+			} {
+				topology, err := ParseTopology(spec)
+				So(err, ShouldEqual, nil)
+				So(topology, ShouldResemble, expected)
+			}
+
+			for _, spec := range []string{"donut", "T100", "T100+1,50+1", "K30,20", "K30*,20*", "C30*,20", "S30,20", "P30+1,20", "T0,20", "X30,20"} {
+				_, err := ParseTopology(spec)
+				So(err, ShouldResemble, errors.New("Invalid topology \""+spec+"\""))
+			}
+		})
+
+		Convey("Impossible worlds", func() {
+			_, err := NewSphereWorld(3, 4)
+			So(err, ShouldResemble, errors.New("Impossible world"))
+
+			_, err = NewShiftedTorusWorld(3, 4, 1, 1)
+			So(err, ShouldResemble, errors.New("Only one of the edges of a torus can be shifted"))
+		})
+
+		Convey("The size in the topology wins", func() {
+			topology, _ := ParseTopology("K30*,20")
+			world, err := NewTopologyWorld(5, 5, topology)
+			So(err, ShouldEqual, nil)
+			So(world.Height, ShouldEqual, 20)
+			So(world.Width, ShouldEqual, 30)
+		})
+
+		Convey("Sphere corners", func() {
+			world, _ := NewSphereWorld(4, 4)
+
+			So(world.GetCellNeighboursCoords(NewCoord(1, 0)), ShouldResemble, NeighboursCoords{
+				NewCoord(0, 0), // the north-west neighbour is the west one too
+				NewCoord(0, 1),
+				NewCoord(0, 2),
+				NewCoord(2, 0),
+				NewCoord(2, 1),
+				NewCoord(1, 1),
+				NewCoord(0, 1),
+				NewCoord(0, 0),
+			})
+
+			// Crossing both the top and left edges leads nowhere
+			So(len(world.GetCellNeighboursCoords(NewCoord(0, 0))), ShouldEqual, 7)
+		})
+
+		// The glider goes south-east on a plane as well, and where its cells are
+		// is then taken across the seam by hand
+		gliderCrossing := func(world World, x, y int, across func(x, y int) Coord) {
+			const margin = 20
+
+			plane, _ := NewWorld(world.Height+2*margin, world.Width+2*margin)
+
+			for _, c := range []Coord{NewCoord(1, 0), NewCoord(2, 1), NewCoord(0, 2), NewCoord(1, 2), NewCoord(2, 2)} {
+				cx, cy := c.Get()
+				world.ActivateCell(NewCoord(x+cx, y+cy))
+				plane.ActivateCell(NewCoord(x+cx+margin, y+cy+margin))
+			}
+
+			generator := NewGenerator(&world)
+			planeGenerator := NewGenerator(&plane)
+
+			for i := 0; i < 16; i++ {
+				generator.Step()
+				planeGenerator.Step()
+
+				expected, actual := map[Coord]bool{}, map[Coord]bool{}
+
+				plane.ForEachCoordinate(func(c Coord) {
+					if plane.ActiveMatrix.IsLive(c) {
+						cx, cy := c.Get()
+						expected[across(cx-margin, cy-margin)] = true
+					}
+				})
+
+				world.ForEachCoordinate(func(c Coord) {
+					if world.ActiveMatrix.IsLive(c) {
+						actual[c] = true
+					}
+				})
+
+				So(actual, ShouldResemble, expected)
+			}
+		}
+
+		Convey("Gliders crossing the seams", func() {
+			Convey("Of a shifted torus", func() {
+				world, _ := NewShiftedTorusWorld(8, 8, 0, 2)
+
+				gliderCrossing(world, 4, 1, func(x, y int) Coord {
+					if x >= 8 {
+						return NewCoord(x-8, (y+2)%8)
+					}
+
+					return NewCoord(x, y)
+				})
+			})
+
+			Convey("Of a Klein bottle twisted on the top and bottom", func() {
+				world, _ := NewKleinBottleWorld(8, 8, true)
+
+				gliderCrossing(world, 1, 4, func(x, y int) Coord {
+					if y >= 8 {
+						return NewCoord(7-x, y-8)
+					}
+
+					return NewCoord(x, y)
+				})
+			})
+
+			Convey("Of a Klein bottle twisted on the left and right", func() {
+				world, _ := NewKleinBottleWorld(8, 8, false)
+
+				gliderCrossing(world, 4, 1, func(x, y int) Coord {
+					if x >= 8 {
+						return NewCoord(x-8, 7-y)
+					}
+
+					return NewCoord(x, y)
+				})
+			})
+
+			Convey("Of a cross-surface", func() {
+				world, _ := NewCrossSurfaceWorld(8, 8)
+
+				gliderCrossing(world, 1, 4, func(x, y int) Coord {
+					if y >= 8 {
+						return NewCoord(7-x, y-8)
+					}
+
+					return NewCoord(x, y)
+				})
+
+				// Big enough for it not to get close to the bottom edge
+				world, _ = NewCrossSurfaceWorld(12, 12)
+
+				gliderCrossing(world, 8, 4, func(x, y int) Coord {
+					if x >= 12 {
+						return NewCoord(x-12, 11-y)
+					}
+
+					return NewCoord(x, y)
+				})
+			})
+
+			Convey("Of a sphere", func() {
+				world, _ := NewSphereWorld(12, 12)
+
+				// Going down the bottom edge, it comes from the right edge going left
+				gliderCrossing(world, 2, 6, func(x, y int) Coord {
+					if y >= 12 {
+						return NewCoord(23-y, x)
+					}
+
+					return NewCoord(x, y)
+				})
+			})
+		})
+	})
+
+	Convey("Unbounded world", t, func() {
+		world := NewUnboundedWorld()
+
+		So(world.IsCoordValid(NewCoord(-1000000000000, 1000000000000)), ShouldBeTrue)
+
+		// A glider going south-east
+		for _, c := range []Coord{NewCoord(1, 0), NewCoord(2, 1), NewCoord(0, 2), NewCoord(1, 2), NewCoord(2, 2)} {
+			world.ActivateCell(c)
+		}
+
+		generator := NewGenerator(&world)
+
+		Convey("Gliders go on forever", func() {
+			for i := 0; i < 400; i++ {
+				generator.Step()
+			}
+
+			topLeft, bottomRight, found := world.Bounds()
+
+			So(found, ShouldBeTrue)
+			So(topLeft, ShouldResemble, NewCoord(100, 100))
+			So(bottomRight, ShouldResemble, NewCoord(102, 102))
+
+			// Nothing is left behind
+			So(len(world.ActiveMatrix), ShouldBeLessThan, 30)
+		})
+
+		Convey("The printer shows a viewport", func() {
+			printer := NewViewportPrinter(&world, Viewport{NewCoord(-1, -1), 4, 5}, false)
+
+			So(printer.Print(), ShouldEqual, "#######\n#     #\n#  o  #\n#   o #\n# ooo #\n#######\n")
+
+			for i := 0; i < 40; i++ {
+				generator.Step()
+			}
+
+			So(printer.Print(), ShouldEqual, "#######\n#     #\n#     #\n#     #\n#     #\n#######\n")
+		})
+
+		Convey("And can follow the cells", func() {
+			printer := NewViewportPrinter(&world, Viewport{NewCoord(0, 0), 5, 5}, true)
+
+			for i := 0; i < 40; i++ {
+				generator.Step()
+			}
+
+			So(printer.Print(), ShouldEqual, "#######\n#     #\n#  o  #\n#   o #\n# ooo #\n#     #\n#######\n")
+			So(printer.Viewport.Origin, ShouldResemble, NewCoord(9, 9))
+
+			// It only moves when the cells go out of it
+			generator.Step()
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "#######\n#     #\n#     #\n#   o #\n# o o #\n#  oo #\n#######\n")
+			So(printer.Viewport.Origin, ShouldResemble, NewCoord(9, 9))
+		})
+
+		Convey("From a topology", func() {
+			topology, err := ParseTopology("unbounded")
+			So(err, ShouldEqual, nil)
+
+			world, err := NewTopologyWorld(10, 10, topology)
+			So(err, ShouldEqual, nil)
+			So(world.Unbounded, ShouldBeTrue)
+		})
+	})
+
+	Convey("Boundary conditions", t, func() {
+		Convey("Invalid boundaries", func() {
+			_, err := NewBoundedWorld(3, 3, Boundaries{North: "bouncy"})
+			So(err, ShouldResemble, errors.New("Unknown boundary condition \"bouncy\""))
+
+			_, err = NewBoundedWorld(3, 3, Boundaries{North: WrapBoundary, South: MirrorBoundary})
+			So(err, ShouldResemble, errors.New("Wrapping edges must be opposite to another wrapping edge"))
+
+			_, err = NewBoundedWorld(0, 3, Boundaries{})
+			So(err, ShouldResemble, errors.New("Impossible world"))
+		})
+
+		Convey("Mirror", func() {
+			world, _ := NewBoundedWorld(3, 3, Boundaries{North: MirrorBoundary, West: MirrorBoundary})
+
+			So(world.GetCellNeighboursCoords(NewCoord(0, 0)), ShouldResemble, NeighboursCoords{
+				NewCoord(0, 0),
+				NewCoord(0, 0),
+				NewCoord(1, 0),
+				NewCoord(1, 0),
+				NewCoord(1, 1),
+				NewCoord(0, 1),
+				NewCoord(0, 1),
+				NewCoord(0, 0),
+			})
+
+			// The east and south edges still clip
+			So(len(world.GetCellNeighboursCoords(NewCoord(2, 2))), ShouldEqual, 3)
+
+			Convey("Half a block next to a mirror is still a block", func() {
+				world, _ := NewBoundedWorld(4, 3, Boundaries{West: MirrorBoundary})
+
+				world.ActivateCell(NewCoord(0, 1))
+				world.ActivateCell(NewCoord(0, 2))
+
+				generator := NewGenerator(&world)
+				printer := NewPrinter(&world)
+
+				generator.Step()
+				So(printer.Print(), ShouldEqual, "#####\n#   #\n#o  #\n#o  #\n#   #\n#####\n")
+			})
+		})
+
+		Convey("Live borders give birth to cells", func() {
+			world, _ := NewBoundedWorld(3, 4, Boundaries{North: LiveBoundary})
+
+			So(world.CountLiveNeighbours(NewCoord(1, 0)), ShouldEqual, 3)
+			So(world.CountLiveNeighbours(NewCoord(1, 1)), ShouldEqual, 0)
+
+			// There are live neighbours beyond, not just missing ones
+			So(len(world.GetCellNeighboursCoords(NewCoord(1, 0))), ShouldEqual, 8)
+
+			generator := NewGenerator(&world)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "######\n# oo #\n#    #\n#    #\n######\n")
+
+			_, err := world.GetCell(liveBorderCoord)
+			So(err, ShouldResemble, errors.New("Invalid coord"))
+
+			exporter := NewExporter(&world)
+			pattern, _ := exporter.Capture()
+			So(pattern.Specie, ShouldResemble, Specie{{1, 1}})
+		})
+
+		Convey("Dead borders are there, but dead", func() {
+			world, _ := NewBoundedWorld(3, 3, Boundaries{West: DeadBoundary, East: DeadBoundary, North: DeadBoundary, South: DeadBoundary})
+
+			So(len(world.GetCellNeighboursCoords(NewCoord(0, 0))), ShouldEqual, 8)
+			So(world.CountLiveNeighbours(NewCoord(0, 0)), ShouldEqual, 0)
+		})
+
+		Convey("Wrap", func() {
+			world, _ := NewBoundedWorld(3, 3, Boundaries{West: WrapBoundary, East: WrapBoundary, North: DeadBoundary})
+
+			So(world.GetCellNeighboursCoords(NewCoord(0, 1)), ShouldContain, NewCoord(2, 1))
+			So(world.GetCellNeighboursCoords(NewCoord(0, 0)), ShouldContain, deadBorderCoord)
+		})
+
+		Convey("Absorbing edges swallow cells", func() {
+			world, _ := NewBoundedWorld(4, 4, Boundaries{South: AbsorbingBoundary})
+
+			world.ActivateCell(NewCoord(1, 1))
+			world.ActivateCell(NewCoord(1, 2))
+			world.ActivateCell(NewCoord(1, 3))
+
+			generator := NewGenerator(&world)
+			printer := NewPrinter(&world)
+
+			generator.Step()
+			So(printer.Print(), ShouldEqual, "######\n#    #\n#    #\n#ooo #\n#    #\n######\n")
+
+			So(world.IsAbsorbed(NewCoord(2, 3)), ShouldBeTrue)
+			So(world.IsAbsorbed(NewCoord(2, 2)), ShouldBeFalse)
+		})
+
+		Convey("From the configuration", func() {
+			config, err := ParseConfig(`{"Boundaries": {"North": "mirror", "East": "live"}}`)
+			So(err, ShouldEqual, nil)
+			So(config.Boundaries, ShouldResemble, Boundaries{North: MirrorBoundary, East: LiveBoundary})
+		})
+	})
+
+	Convey("Dense engine", t, func() {
+		soup := func(world *World, seed int64) {
+			random := rand.New(rand.NewSource(seed))
+			h, w := world.Size()
+
+			for i := 0; i < h*w/3; i++ {
+				world.ActivateCell(NewCoord(random.Intn(w), random.Intn(h)))
+			}
+		}
+
+		liveCells := func(world *World) map[Coord]bool {
+			cells := map[Coord]bool{}
+
+			world.ForEachCoordinate(func(c Coord) {
+				if world.ActiveMatrix.IsLive(c) {
+					cells[c] = true
+				}
+			})
+
+			return cells
+		}
+
+		Convey("Gives the same results as the generator", func() {
+			for _, rule := range []string{"B3/S23", "B36/S23", "B2/S", "B3678/S34678", "B1/S012345678"} {
+				for _, size := range [][2]int{{20, 70}, {9, 64}, {12, 130}, {5, 3}} {
+					for _, circular := range []bool{false, true} {
+						create := NewWorld
+
+						if circular {
+							create = NewCircularWorld
+						}
+
+						sparse, _ := create(size[0], size[1])
+						dense, _ := create(size[0], size[1])
+
+						soup(&sparse, int64(size[0]*size[1]))
+						soup(&dense, int64(size[0]*size[1]))
+
+						ruleset, _ := ParseRule(rule)
+
+						generator := NewRulesetGenerator(&sparse, ruleset)
+						denseGenerator, err := NewDenseGenerator(&dense, ruleset)
+						So(err, ShouldEqual, nil)
+						So(denseGenerator.Circular, ShouldEqual, circular)
+
+						for i := 0; i < 10; i++ {
+							generator.Step()
+							denseGenerator.Step()
+						}
+
+						denseGenerator.Sync()
+
+						So(liveCells(&dense), ShouldResemble, liveCells(&sparse))
+					}
+				}
+			}
+		})
+
+		Convey("Only for life-like rules", func() {
+			world, _ := NewWorld(3, 3)
+
+			for _, rule := range []string{"B2/S3V", "B2/S34H", "B2-a/S12", "B2/S/C3", "R2,C0,M0,S2..3,B3..3,NM"} {
+				ruleset, _ := ParseRule(rule)
+				_, err := NewDenseGenerator(&world, ruleset)
+				So(err, ShouldResemble, errors.New("The dense engine only supports life-like rules on the Moore neighbourhood, not "+ruleset.String()))
+			}
+		})
+
+		Convey("Only for planes and tori", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			klein, _ := NewKleinBottleWorld(4, 4, true)
+			shifted, _ := NewShiftedTorusWorld(4, 4, 1, 0)
+			mirrored, _ := NewBoundedWorld(4, 4, Boundaries{North: MirrorBoundary})
+			dead, _ := NewBoundedWorld(4, 4, Boundaries{North: DeadBoundary})
+			hexagonal, _ := NewWorld(4, 4)
+			hexagonal.SetGrid(HexagonalGrid)
+			unbounded := NewUnboundedWorld()
+
+			for _, world := range []*World{&klein, &shifted, &mirrored, &dead, &hexagonal, &unbounded} {
+				_, err := NewDenseGenerator(world, conway)
+				So(err, ShouldResemble, errors.New("The dense engine only supports square planes and tori"))
+			}
+		})
+
+		Convey("Steps without allocating", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			for _, create := range []func(h, w int) (World, error){NewWorld, NewCircularWorld} {
+				world, _ := create(200, 300)
+
+				random := rand.New(rand.NewSource(1))
+
+				for i := 0; i < 20000; i++ {
+					world.ActivateCell(NewCoord(random.Intn(300), random.Intn(200)))
+				}
+
+				generator, _ := NewDenseGenerator(&world, conway)
+
+				So(testing.AllocsPerRun(10, generator.Step), ShouldEqual, 0)
+				So(generator.Population(), ShouldBeGreaterThan, 0)
+			}
+		})
+	})
+
+	Convey("HashLife engine", t, func() {
+		importer := NewSpecieImporter()
+
+		gun, _ := importer.ImportFromRLEString("x = 36, y = 9\n24bo$22bobo$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o$2o8bo3bob2o4bobo$10bo5bo7bo$11bo3bo$12b2o!")
+		glider := Specie{{0, 1, 0}, {0, 0, 1}, {1, 1, 1}}
+		rPentomino := Specie{{0, 1, 1}, {1, 1, 0}, {0, 1, 0}}
+
+		liveCells := func(world *World) map[Coord]bool {
+			cells := map[Coord]bool{}
+
+			world.ForEachCoordinate(func(c Coord) {
+				if world.ActiveMatrix.IsLive(c) {
+					cells[c] = true
+				}
+			})
+
+			return cells
+		}
+
+		place := func(specie Specie, coord Coord) World {
+			world := NewUnboundedWorld()
+			placer := NewLifePlacer(&world)
+			placer.Place(specie, coord)
+			return world
+		}
+
+		Convey("Gives the same results as the generator", func() {
+			for _, rule := range []string{"B3/S23", "B36/S23", "B3678/S34678", "B2/S"} {
+				ruleset, _ := ParseRule(rule)
+
+				sparse := place(rPentomino, NewCoord(-7, 3))
+				hashed := place(rPentomino, NewCoord(-7, 3))
+
+				generator := NewRulesetGenerator(&sparse, ruleset)
+				hashLife, err := NewHashLifeGenerator(&hashed, ruleset)
+				So(err, ShouldEqual, nil)
+
+				for _, k := range []uint{0, 0, 1, 3, 0, 2, 4} {
+					for i := 0; i < 1<<k; i++ {
+						generator.Step()
+					}
+
+					So(hashLife.StepPow2(k), ShouldEqual, nil)
+					hashLife.Sync()
+
+					So(liveCells(&hashed), ShouldResemble, liveCells(&sparse))
+					So(hashLife.Population(), ShouldEqual, len(liveCells(&sparse)))
+				}
+
+				So(hashLife.Generation, ShouldEqual, 33)
+			}
+		})
+
+		Convey("Glider gun", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			sparse := place(gun.Specie, NewCoord(0, 0))
+			hashed := place(gun.Specie, NewCoord(0, 0))
+
+			generator := NewRulesetGenerator(&sparse, conway)
+			hashLife, _ := NewHashLifeGenerator(&hashed, conway)
+
+			for i := 0; i < 256; i++ {
+				generator.Step()
+			}
+
+			hashLife.StepPow2(8)
+			hashLife.Sync()
+
+			So(liveCells(&hashed), ShouldResemble, liveCells(&sparse))
+		})
+
+		Convey("A glider a million generations later", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			world := place(glider, NewCoord(0, 0))
+			hashLife, _ := NewHashLifeGenerator(&world, conway)
+
+			So(hashLife.StepPow2(20), ShouldEqual, nil)
+			So(hashLife.Generation, ShouldEqual, 1<<20)
+			So(hashLife.Population(), ShouldEqual, 5)
+
+			hashLife.Sync()
+
+			expected := place(glider, NewCoord(1<<18, 1<<18))
+			So(liveCells(&world), ShouldResemble, liveCells(&expected))
+		})
+
+		Convey("Forgets nodes beyond the memory budget", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			sparse := place(gun.Specie, NewCoord(0, 0))
+			hashed := place(gun.Specie, NewCoord(0, 0))
+
+			generator := NewRulesetGenerator(&sparse, conway)
+			hashLife, _ := NewHashLifeGenerator(&hashed, conway)
+			hashLife.MemoryBudget = 1
+
+			for i := 0; i < 100; i++ {
+				generator.Step()
+				hashLife.Step()
+			}
+
+			hashLife.Sync()
+
+			So(liveCells(&hashed), ShouldResemble, liveCells(&sparse))
+			So(len(hashLife.tree.nodes), ShouldBeLessThan, 1000)
+		})
+
+		Convey("Keeps the results of steps of all sizes", func() {
+			conway, _ := ParseRule(DefaultRule)
+
+			sparse := place(gun.Specie, NewCoord(0, 0))
+			hashed := place(gun.Specie, NewCoord(0, 0))
+
+			generator := NewRulesetGenerator(&sparse, conway)
+			hashLife, _ := NewHashLifeGenerator(&hashed, conway)
+
+			// 1, 2 and 4 generations at once, each time
+			for i := 0; i < 10; i++ {
+				hashLife.StepN(7)
+			}
+
+			for i := 0; i < 70; i++ {
+				generator.Step()
+			}
+
+			hashLife.Sync()
+			So(liveCells(&hashed), ShouldResemble, liveCells(&sparse))
+
+			steps := make(map[uint]bool)
+
+			for key := range hashLife.tree.results {
+				steps[key.stepPow2] = true
+			}
+
+			So(steps[0] && steps[1] && steps[2], ShouldBeTrue)
+
+			// Running it again finds all the results in the cache
+			results := len(hashLife.tree.results)
+
+			world := place(gun.Specie, NewCoord(0, 0))
+			again, _ := NewHashLifeGenerator(&world, conway)
+			again.tree = hashLife.tree
+
+			cells := make([]Coord, 0)
+
+			for coord := range liveCells(&world) {
+				cells = append(cells, coord)
+			}
+
+			again.root, again.origin = again.tree.fromCoords(cells)
+
+			for i := 0; i < 10; i++ {
+				again.StepN(7)
+			}
+
+			So(len(again.tree.results), ShouldEqual, results)
+		})
+
+		Convey("Too big steps", func() {
+			conway, _ := ParseRule(DefaultRule)
+			world := NewUnboundedWorld()
+			hashLife, _ := NewHashLifeGenerator(&world, conway)
+
+			So(hashLife.StepPow2(49), ShouldResemble, errors.New("Cannot step 2^49 generations at once, 2^48 at most"))
+		})
+
+		Convey("Only for life-like rules on unbounded worlds", func() {
+			unbounded := NewUnboundedWorld()
+
+			for _, rule := range []string{"B2/S3V", "B2-a/S12", "B2/S/C3"} {
+				ruleset, _ := ParseRule(rule)
+				_, err := NewHashLifeGenerator(&unbounded, ruleset)
+				So(err, ShouldResemble, errors.New("The HashLife engine only supports life-like rules on the Moore neighbourhood, not "+ruleset.String()))
+			}
+
+			conway, _ := ParseRule(DefaultRule)
+			world, _ := NewWorld(10, 10)
+			_, err := NewHashLifeGenerator(&world, conway)
+			So(err, ShouldResemble, errors.New("The HashLife engine only runs on unbounded square worlds"))
+		})
+
+		Convey("Macrocell", func() {
+			Convey("Import", func() {
+				content := "[M2] (golly 2.0)\n#R B3/S23\n#G 12\n#N Two gliders\n.*$..*$***$\n$$$$$$$.*$\n4 1 0 0 2\n"
+
+				pattern, err := importer.Import(MacrocellFormat, content)
+				So(err, ShouldEqual, nil)
+				So(pattern.Name, ShouldEqual, "Two gliders")
+				So(pattern.Rule, ShouldEqual, "B3/S23")
+
+				h, w := pattern.Specie.Size()
+				So(h, ShouldEqual, 16)
+				So(w, ShouldEqual, 10)
+				So(pattern.Specie[0][:3], ShouldResemble, []int{0, 1, 0})
+				So(pattern.Specie[15][9], ShouldEqual, 1)
+			})
+
+			Convey("Export", func() {
+				pattern := NewPattern(glider)
+				pattern.Name = "Glider"
+
+				content, err := ExportPattern(MacrocellFormat, pattern)
+				So(err, ShouldEqual, nil)
+				So(content, ShouldEqual, "[M2] (toy_gameoflife)\n#R B3/S23\n#N Glider\n.*$..*$***$\n")
+
+				content, _ = ExportPattern(MacrocellFormat, gun)
+				imported, err := importer.Import(MacrocellFormat, content)
+				So(err, ShouldEqual, nil)
+				So(imported.Specie, ShouldResemble, gun.Specie)
+			})
+
+			Convey("Loaded into the engine, as in Golly", func() {
+				conway, _ := ParseRule(DefaultRule)
+				world := NewUnboundedWorld()
+				hashLife, _ := NewHashLifeGenerator(&world, conway)
+
+				metadata, err := hashLife.LoadMacrocell("[M2] (golly 2.0)\n#G 12\n.*$..*$***$\n4 0 0 0 1\n")
+				So(err, ShouldEqual, nil)
+				So(metadata.Rule, ShouldEqual, "")
+				So(hashLife.Generation, ShouldEqual, 12)
+
+				hashLife.Sync()
+
+				expected := place(glider, NewCoord(0, 0))
+				So(liveCells(&world), ShouldResemble, liveCells(&expected))
+
+				hashLife.StepPow2(2)
+				So(hashLife.Macrocell(PatternMetadata{}), ShouldEqual, "[M2] (toy_gameoflife)\n#R B3/S23\n#G 16\n$..*$...*$.***$\n4 1 0 0 0\n5 0 0 0 2\n")
+			})
+
+			Convey("Saved and loaded again in the same place", func() {
+				conway, _ := ParseRule(DefaultRule)
+
+				for _, position := range []Coord{{0, 0}, {-37, 5}, {100, -3}, {-1, -1}} {
+					world := place(gun.Specie, position)
+					hashLife, _ := NewHashLifeGenerator(&world, conway)
+					hashLife.StepN(45)
+
+					expected := make(map[Coord]bool)
+
+					hashLife.ForEachLiveCell(func(coord Coord) {
+						expected[coord] = true
+					})
+
+					loadedWorld := NewUnboundedWorld()
+					loaded, _ := NewHashLifeGenerator(&loadedWorld, conway)
+					_, err := loaded.LoadMacrocell(hashLife.Macrocell(PatternMetadata{}))
+					So(err, ShouldEqual, nil)
+
+					loaded.Sync()
+					So(liveCells(&loadedWorld), ShouldResemble, expected)
+					So(loaded.Generation, ShouldEqual, 45)
+				}
+			})
+
+			Convey("Placed among other cells, without building a specie", func() {
+				conway, _ := ParseRule(DefaultRule)
+				content, _ := ExportPattern(MacrocellFormat, gun)
+
+				for _, position := range []Coord{{0, 0}, {-37, 5}, {100, -3}, {3, 0}, {0, 3}} {
+					expected := place(gun.Specie, position)
+					expected.ActivateCell(NewCoord(-10, -10))
+					expected.ActivateCell(NewCoord(50, 20))
+
+					world := NewUnboundedWorld()
+					world.ActivateCell(NewCoord(-10, -10))
+					world.ActivateCell(NewCoord(50, 20))
+
+					hashLife, _ := NewHashLifeGenerator(&world, conway)
+					metadata, err := hashLife.PlaceMacrocell(content, position)
+					So(err, ShouldEqual, nil)
+					So(metadata.Rule, ShouldEqual, "B3/S23")
+
+					hashLife.Sync()
+					So(liveCells(&world), ShouldResemble, liveCells(&expected))
+				}
+			})
+
+			Convey("Invalid files", func() {
+				for content, expected := range map[string]string{
+					"#R B3/S23\n":          "Missing \"[M2]\" header",
+					"[M2]\n#R B3/S23\n":    "The pattern has no nodes",
+					"[M2]\n.*.x\n":         "Expected a \"level nw ne sw se\" node but found \".*.x\" on line 2, column 1",
+					"[M2]\n.........*$\n":  "Leaf cells must fit in 8x8 on line 2, column 9",
+					"[M2]\n*\n5 1 0 0 0\n": "Node 1 is not of level 4 on line 3, column 1",
+					"[M2]\n*\n4 1 0 0 2\n": "Invalid node \"2\" on line 3, column 1",
+					"[M2]\n*\n3 1 0 0 0\n": "Invalid level \"3\" on line 3, column 1",
+					"[M2]\n#G many\n*\n":   "Invalid generation \"many\" on line 2, column 1",
+				} {
+					_, err := importer.ImportFromMacrocellString(content)
+					So(err, ShouldResemble, errors.New(expected))
+				}
+			})
+		})
+	})
+
+	Convey("Parallel steps", t, func() {
+		soup := func(world *World, seed int64) {
+			random := rand.New(rand.NewSource(seed))
+
+			for i := 0; i < 400; i++ {
+				world.ActivateCell(NewCoord(random.Intn(40), random.Intn(30)))
+			}
+		}
+
+		create := func(rule string) World {
+			if rule == "B2/S34H" {
+				world, _ := NewCircularWorld(30, 40)
+				world.SetGrid(HexagonalGrid)
+				return world
+			}
+
+			world, _ := NewBoundedWorld(30, 40, Boundaries{North: AbsorbingBoundary, South: LiveBoundary, East: WrapBoundary, West: WrapBoundary})
+			return world
+		}
+
+		Convey("Give the same results as sequential ones", func() {
+			for _, rule := range []string{"B3/S23", "B2/S/C3", "B2-a/S12", "R2,C0,M1,S4..8,B5..6,NM", "B2/S34H"} {
+				for _, workers := range []int{2, 3, 8, 50} {
+					ruleset, _ := ParseRule(rule)
+
+					sequentialWorld, parallelWorld := create(rule), create(rule)
+
+					soup(&sequentialWorld, int64(workers))
+					soup(&parallelWorld, int64(workers))
+
+					sequential := NewRulesetGenerator(&sequentialWorld, ruleset)
+					parallel := NewRulesetGenerator(&parallelWorld, ruleset)
+					parallel.Workers = workers
+
+					for i := 0; i < 10; i++ {
+						sequential.Step()
+						parallel.Step()
+
+						So(parallelWorld.ActiveMatrix, ShouldResemble, sequentialWorld.ActiveMatrix)
+					}
+				}
+			}
+		})
+
+		Convey("On an empty world", func() {
+			world := NewUnboundedWorld()
+			generator := NewGenerator(&world)
+			generator.Workers = 4
+			generator.Step()
+
+			So(len(world.ActiveMatrix), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Engines", t, func() {
+		conway, _ := ParseRule(DefaultRule)
+		glider := Specie{{0, 1, 0}, {0, 0, 1}, {1, 1, 1}}
+
+		engines := func() map[string]Engine {
+			sparseWorld, _ := NewWorld(20, 20)
+			denseWorld, _ := NewWorld(20, 20)
+			hashLifeWorld := NewUnboundedWorld()
+
+			sparse, _ := NewEngine("", &sparseWorld, conway)
+			dense, _ := NewEngine("dense", &denseWorld, conway)
+			hashLife, _ := NewEngine("hashlife", &hashLifeWorld, conway)
+
+			return map[string]Engine{"sparse": sparse, "dense": dense, "hashlife": hashLife}
+		}
+
+		Convey("All work the same way", func() {
+			for _, engine := range engines() {
+				placer := NewLifePlacer(engine)
+				So(placer.Place(glider, NewCoord(2, 3)), ShouldEqual, nil)
+				So(engine.Population(), ShouldEqual, 5)
+
+				engine.StepN(8)
+
+				So(engine.Population(), ShouldEqual, 5)
+
+				cell, err := engine.GetCell(NewCoord(5, 7))
+				So(err, ShouldEqual, nil)
+				So(cell, ShouldEqual, LiveCell)
+
+				topLeft, bottomRight, found := engine.Bounds()
+				So(found, ShouldBeTrue)
+				So(topLeft, ShouldResemble, NewCoord(4, 5))
+				So(bottomRight, ShouldResemble, NewCoord(6, 7))
+
+				So(engine.SetCell(NewCoord(10, 10), LiveCell), ShouldEqual, nil)
+				So(engine.SetCell(NewCoord(5, 7), DeadCell), ShouldEqual, nil)
+				So(engine.Population(), ShouldEqual, 5)
+
+				printer := NewViewportPrinter(engine, Viewport{Origin: NewCoord(4, 5), Height: 3, Width: 3}, false)
+				So(printer.Print(), ShouldEqual, "#####\n# o #\n#  o#\n#o o#\n#####\n")
+
+				exporter := NewExporter(engine)
+				pattern, err := exporter.Capture()
+				So(err, ShouldEqual, nil)
+				So(pattern.Specie, ShouldResemble, Specie{
+					{0, 1, 0, 0, 0, 0, 0},
+					{0, 0, 1, 0, 0, 0, 0},
+					{1, 0, 1, 0, 0, 0, 0},
+					{0, 0, 0, 0, 0, 0, 0},
+					{0, 0, 0, 0, 0, 0, 0},
+					{0, 0, 0, 0, 0, 0, 1},
+				})
+			}
+		})
+
+		Convey("Bounded ones reject cells out of the world", func() {
+			for name, engine := range engines() {
+				err := engine.SetCell(NewCoord(-1, 30), LiveCell)
+
+				if name == "hashlife" {
+					So(err, ShouldEqual, nil)
+					So(engine.Population(), ShouldEqual, 1)
+					continue
+				}
+
+				So(err, ShouldResemble, errors.New("Invalid coord"))
+			}
+		})
+
+		Convey("By their names", func() {
+			world, _ := NewWorld(3, 3)
+
+			_, err := NewEngine("quantum", &world, conway)
+			So(err, ShouldResemble, errors.New("Unknown engine \"quantum\""))
+
+			_, err = NewEngine("hashlife", &world, conway)
+			So(err, ShouldResemble, errors.New("The HashLife engine only runs on unbounded square worlds"))
+		})
+	})
+
+	Convey("Active region tracking", t, func() {
+		soup := func(world *World, seed int64) {
+			random := rand.New(rand.NewSource(seed))
+
+			for i := 0; i < 500; i++ {
+				world.ActivateCell(NewCoord(random.Intn(60), random.Intn(40)))
+			}
+		}
+
+		Convey("Gives the same results as evaluating every cell", func() {
+			worlds := []func() World{
+				func() World {
+					world, _ := NewKleinBottleWorld(40, 60, true)
+					return world
+				},
+				func() World {
+					world, _ := NewBoundedWorld(40, 60, Boundaries{North: LiveBoundary, South: AbsorbingBoundary, East: MirrorBoundary, West: DeadBoundary})
+					return world
+				},
+				func() World {
+					world, _ := NewCircularWorld(40, 60)
+					return world
+				},
+				func() World {
+					world, _ := NewWorld(40, 60)
+					return world
+				},
+				NewUnboundedWorld,
+			}
+
+			skipped := uint64(0)
+
+			for _, rule := range []string{"B3/S23", "B2/S/C3", "B2-a/S12"} {
+				for i, create := range worlds {
+					ruleset, _ := ParseRule(rule)
+
+					tracked, full := create(), create()
+					soup(&tracked, int64(i))
+					soup(&full, int64(i))
+
+					trackedGenerator := NewRulesetGenerator(&tracked, ruleset)
+					fullGenerator := NewRulesetGenerator(&full, ruleset)
+
+					for step := 0; step < 40; step++ {
+						trackedGenerator.Step()
+
+						fullGenerator.activeTiles = nil
+						fullGenerator.Step()
+					}
+
+					So(tracked.ActiveMatrix, ShouldResemble, full.ActiveMatrix)
+
+					skipped += trackedGenerator.Counters.SkippedCells
+				}
+			}
+
+			So(skipped, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Cells set between steps wake their tiles up", func() {
+			world, _ := NewWorld(40, 40)
+			placer := NewLifePlacer(&world)
+			placer.Place(Specie{{1, 1}, {1, 1}}, NewCoord(5, 5))
+
+			generator := NewGenerator(&world)
+			generator.Step()
+			generator.Step()
+
+			So(generator.Counters.ActiveTiles, ShouldEqual, 0)
+
+			// Next to the block, making it a different pattern
+			world.ActivateCell(NewCoord(7, 5))
+			generator.Step()
+
+			expected, _ := NewWorld(40, 40)
+			expectedPlacer := NewLifePlacer(&expected)
+			expectedPlacer.Place(Specie{{1, 1, 1}, {1, 1, 0}}, NewCoord(5, 5))
+			expectedGenerator := NewGenerator(&expected)
+			expectedGenerator.Step()
+
+			So(world.ActiveMatrix, ShouldResemble, expected.ActiveMatrix)
+		})
+
+		Convey("Counts the work skipped on still lifes", func() {
+			world, _ := NewWorld(64, 64)
+			placer := NewLifePlacer(&world)
+
+			for _, coord := range []Coord{{2, 2}, {40, 2}, {2, 40}, {40, 40}} {
+				placer.Place(Specie{{1, 1}, {1, 1}}, coord)
+			}
+
+			placer.Place(Specie{{1, 1, 1}}, NewCoord(20, 20))
+
+			generator := NewGenerator(&world)
+
+			// 4 blocks of 4 live cells and 12 around each, and 3 live cells and 12 around the blinker
+			generator.Step()
+			So(generator.Counters, ShouldResemble, ActivityCounters{EvaluatedCells: 79, SkippedCells: 0, ActiveTiles: 1})
+
+			generator.Step()
+			So(generator.Counters, ShouldResemble, ActivityCounters{EvaluatedCells: 94, SkippedCells: 64, ActiveTiles: 1})
+		})
+	})
+
+	Convey("Period detection", t, func() {
+		conway, _ := ParseRule(DefaultRule)
+
+		// Steps the engine until something is found, at most the given generations
+		detect := func(engine Engine, detector *PeriodDetector, generations uint64) (Finding, bool) {
+			for generation := uint64(0); generation <= generations; generation++ {
+				if finding, found := detector.Observe(generation); found {
+					return finding, true
+				}
+
+				engine.StepN(1)
+			}
+
+			return Finding{}, false
+		}
+
+		newEngine := func(world *World, specie Specie, ruleset Ruleset, name string) Engine {
+			placer := NewLifePlacer(world)
+			placer.Place(specie, NewCoord(5, 5))
+
+			engine, err := NewEngine(name, world, ruleset)
+			So(err, ShouldEqual, nil)
+
+			return engine
+		}
+
+		Convey("Still lifes", func() {
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1, 1}, {1, 1}}, conway, "sparse")
+			detector := NewPeriodDetector(engine)
+
+			finding, found := detect(engine, &detector, 10)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: StillWorld, Generation: 0, Period: 1})
+			So(finding.String(), ShouldEqual, "The world is still from generation 0")
+		})
+
+		Convey("Patterns that become still", func() {
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1, 1}, {1, 0}}, conway, "dense")
+			detector := NewPeriodDetector(engine)
+
+			finding, found := detect(engine, &detector, 10)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: StillWorld, Generation: 1, Period: 1})
+		})
+
+		Convey("Oscillators", func() {
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1, 1, 1}}, conway, "sparse")
+			detector := NewPeriodDetector(engine)
+
+			finding, found := detect(engine, &detector, 10)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: PeriodicWorld, Generation: 0, Period: 2})
+			So(finding.String(), ShouldEqual, "The world is periodic from generation 0, with period 2")
+		})
+
+		Convey("Spaceships, wherever they are", func() {
+			world := NewUnboundedWorld()
+			engine := newEngine(&world, Specie{{0, 1, 0}, {0, 0, 1}, {1, 1, 1}}, conway, "hashlife")
+			detector := NewPeriodDetector(engine)
+
+			finding, found := detect(engine, &detector, 10)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: PeriodicWorld, Generation: 0, Period: 4, Displacement: NewCoord(1, 1)})
+		})
+
+		Convey("Empty worlds", func() {
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1, 1}}, conway, "sparse")
+			detector := NewPeriodDetector(engine)
+
+			finding, found := detect(engine, &detector, 10)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: EmptyWorld, Generation: 1})
+			So(finding.String(), ShouldEqual, "The world is empty from generation 1")
+		})
+
+		Convey("Dying cells are not the same as live ones", func() {
+			ruleset, _ := ParseRule("B/S/C3")
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1}}, ruleset, "sparse")
+			detector := NewPeriodDetector(engine)
+
+			finding, found := detect(engine, &detector, 10)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: EmptyWorld, Generation: 2})
+		})
+
+		Convey("Generations with the same hash but other cells are told apart", func() {
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1, 1}, {1, 1}}, conway, "sparse")
+			detector := NewPeriodDetector(engine)
+
+			// As if another generation had the same hash
+			collision := detector.observe(0)
+			collision.check++
+			detector.history = append(detector.history, collision)
+
+			_, found := detector.Observe(1)
+			So(found, ShouldBeFalse)
+
+			finding, found := detector.Observe(2)
+			So(found, ShouldBeTrue)
+			So(finding, ShouldResemble, Finding{State: StillWorld, Generation: 1, Period: 1})
+		})
+
+		Convey("Observing only some generations", func() {
+			// Steps the engine 8 generations at a time
+			detectEvery8 := func(engine Engine, detector *PeriodDetector) (Finding, bool) {
+				for generation := uint64(0); generation <= 80; generation += 8 {
+					if finding, found := detector.Observe(generation); found {
+						return finding, true
+					}
+
+					engine.StepN(8)
+				}
+
+				return Finding{}, false
+			}
+
+			content, _ := ioutil.ReadFile(filepath.Join("testdata", "golden", "pulsar.rle"))
+			importer := NewSpecieImporter()
+			pulsar, _ := importer.ImportFromRLEString(string(content))
+
+			for name, c := range map[string]struct {
+				specie   Specie
+				expected Finding
+			}{
+				"still lifes": {Specie{{1, 1}, {1, 1}}, Finding{State: StillWorld, Generation: 0, Period: 1}},
+				"oscillators": {Specie{{1, 1, 1}}, Finding{State: PeriodicWorld, Generation: 0, Period: 2}},
+				"spaceships":  {Specie{{0, 1, 0}, {0, 0, 1}, {1, 1, 1}}, Finding{State: PeriodicWorld, Generation: 0, Period: 4, Displacement: NewCoord(1, 1)}},
+				"pre-blocks":  {Specie{{1, 1}, {1, 0}}, Finding{State: StillWorld, Generation: 8, Period: 1}},
+				"pulsars":     {pulsar.Specie, Finding{State: PeriodicWorld, Generation: 0, Period: 3}},
+			} {
+				Convey("Find the real period of "+name, func() {
+					world := NewUnboundedWorld()
+					engine := newEngine(&world, c.specie, conway, "sparse")
+					detector := NewPeriodDetector(engine)
+
+					finding, found := detectEvery8(engine, &detector)
+					So(found, ShouldBeTrue)
+					So(finding, ShouldResemble, c.expected)
+				})
+			}
+
+			Convey("Leave the engine on the generation observed", func() {
+				glider := Specie{{0, 1, 0}, {0, 0, 1}, {1, 1, 1}}
+
+				for _, name := range []string{"sparse", "dense", "hashlife"} {
+					world, _ := NewWorld(40, 40)
+
+					if name == "hashlife" {
+						world = NewUnboundedWorld()
+					}
+
+					engine := newEngine(&world, glider, conway, name)
+					detector := NewPeriodDetector(engine)
+
+					finding, found := detectEvery8(engine, &detector)
+					So(found, ShouldBeTrue)
+					So(finding.Period, ShouldEqual, 4)
+
+					expectedWorld, _ := NewWorld(40, 40)
+					expected := newEngine(&expectedWorld, glider, conway, "sparse")
+					expected.StepN(8)
+
+					So(liveCellsOf(engine), ShouldResemble, liveCellsOf(expected))
+
+					if hashLife, isHashLife := engine.(*HashLifeGenerator); isHashLife {
+						So(hashLife.Generation, ShouldEqual, 8)
+					}
+				}
+			})
+
+			Convey("Only tell a multiple of it when the universe cannot be stepped", func() {
+				world, _ := NewWorld(20, 20)
+				engine := newEngine(&world, Specie{{1, 1, 1}}, conway, "sparse")
+				detector := NewPeriodDetector(&world)
+
+				_, found := detector.Observe(0)
+				So(found, ShouldBeFalse)
+
+				engine.StepN(8)
+
+				finding, found := detector.Observe(8)
+				So(found, ShouldBeTrue)
+				So(finding, ShouldResemble, Finding{State: PeriodicWorld, Generation: 0, Period: 8, Sampled: true})
+				So(finding.String(), ShouldEqual, "The world is still or periodic from generation 0, with a period dividing 8")
+			})
+		})
+
+		Convey("Periods longer than the ones remembered are not found", func() {
+			world, _ := NewWorld(20, 20)
+			engine := newEngine(&world, Specie{{1, 1, 1}}, conway, "sparse")
+			detector := NewPeriodDetector(engine)
+			detector.MaxPeriod = 1
+
+			_, found := detect(engine, &detector, 10)
+			So(found, ShouldBeFalse)
+		})
+	})
+
+	Convey("Spaceship velocities", t, func() {
+		conway, _ := ParseRule(DefaultRule)
+
+		Convey("In c/p notation", func() {
+			velocity := func(dx, dy int, period uint64) string {
+				return Finding{State: PeriodicWorld, Period: period, Displacement: NewCoord(dx, dy)}.Velocity()
+			}
+
+			So(velocity(1, 1, 4), ShouldEqual, "c/4 diagonal")
+			So(velocity(-1, 1, 4), ShouldEqual, "c/4 diagonal")
+			So(velocity(-2, 0, 4), ShouldEqual, "c/2 orthogonal")
+			So(velocity(0, 2, 5), ShouldEqual, "2c/5 orthogonal")
+			So(velocity(3, 3, 12), ShouldEqual, "c/4 diagonal")
+			So(velocity(1, 0, 1), ShouldEqual, "c orthogonal")
+			So(velocity(1, -2, 6), ShouldEqual, "(2,1)c/6 oblique")
+		})
+
+		Convey("Of imported patterns", func() {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", "golden", "lwss.rle"))
+			So(err, ShouldEqual, nil)
+
+			importer := NewSpecieImporter()
+			pattern, err := importer.ImportFromRLEString(string(content))
+			So(err, ShouldEqual, nil)
+
+			finding, found, err := AnalysePattern(pattern, conway, 100)
+			So(err, ShouldEqual, nil)
+			So(found, ShouldBeTrue)
+			So(finding.Moves(), ShouldBeTrue)
+			So(finding.Displacement, ShouldResemble, NewCoord(-2, 0))
+			So(finding.String(), ShouldEqual, "The world is periodic from generation 0, with period 4, moving at c/2 orthogonal")
+		})
+
+		Convey("Oscillators do not move", func() {
+			finding, found, err := AnalysePattern(NewPattern(Specie{{1, 1, 1}}), conway, 100)
+			So(err, ShouldEqual, nil)
+			So(found, ShouldBeTrue)
+			So(finding.Moves(), ShouldBeFalse)
+			So(finding.String(), ShouldEqual, "The world is periodic from generation 0, with period 2")
+		})
+
+		Convey("Nothing is found in patterns that keep growing", func() {
+			content, _ := ioutil.ReadFile(filepath.Join("testdata", "golden", "gosperglidergun.rle"))
+			importer := NewSpecieImporter()
+			pattern, _ := importer.ImportFromRLEString(string(content))
+
+			_, found, err := AnalysePattern(pattern, conway, 100)
+			So(err, ShouldEqual, nil)
+			So(found, ShouldBeFalse)
+		})
+	})
+
+	Convey("Object census", t, func() {
+		conway, _ := ParseRule(DefaultRule)
+
+		world, _ := NewWorld(80, 80)
+		placer := NewLifePlacer(&world)
+
+		placer.Place(Specie{{1, 1}, {1, 1}}, NewCoord(2, 2))
+		placer.Place(Specie{{1, 1}, {1, 1}}, NewCoord(70, 70))
+		placer.Place(Specie{{0, 1, 1, 0}, {1, 0, 0, 1}, {0, 1, 1, 0}}, NewCoord(20, 2))
+		placer.Place(Specie{{1, 1, 1}}, NewCoord(40, 2))
+		placer.Place(Specie{{1, 1, 0, 0}, {1, 1, 0, 0}, {0, 0, 1, 1}, {0, 0, 1, 1}}, NewCoord(2, 20))
+		placer.Place(Specie{{0, 1, 0}, {0, 0, 1}, {1, 1, 1}}, NewCoord(20, 20))
+		placer.Place(Specie{{0, 1, 1, 0, 0}, {1, 1, 1, 1, 0}, {1, 1, 0, 1, 1}, {0, 0, 1, 1, 0}}, NewCoord(40, 20))
+		placer.Place(Specie{{0, 0, 1, 0, 0, 0, 0, 1, 0, 0}, {1, 1, 0, 1, 1, 1, 1, 0, 1, 1}, {0, 0, 1, 0, 0, 0, 0, 1, 0, 0}}, NewCoord(20, 40))
+
+		Convey("Objects are the groups of cells close to each other", func() {
+			So(len(world.SeparateObjects()), ShouldEqual, 8)
+		})
+
+		importer := NewSpecieImporter()
+
+		golden := func(name string) Specie {
+			content, _ := ioutil.ReadFile(filepath.Join("testdata", "golden", name+".rle"))
+			pattern, _ := importer.ImportFromRLEString(string(content))
+			return pattern.Specie
+		}
+
+		Convey("Objects whose cells do not touch", func() {
+			for _, c := range []struct {
+				name string
+				code string
+				kind ObjectKind
+			}{
+				{"pulsar", "xp3_co9nas0san9oczgoldlo0oldlogz1047210127401", OscillatorObject},
+				{"lwss", "xq4_6frc", SpaceshipObject},
+			} {
+				// In each of their phases
+				for generation := 0; generation < 4; generation++ {
+					other, _ := NewWorld(40, 40)
+					otherPlacer := NewLifePlacer(&other)
+					otherPlacer.Place(golden(c.name), NewCoord(5, 10))
+
+					engine := NewSparseEngine(&other, conway)
+					engine.StepN(uint64(generation))
+
+					census, err := TakeCensus(&other, conway)
+					So(err, ShouldEqual, nil)
+					So(census, ShouldResemble, Census{{Code: c.code, Kind: c.kind, Count: 1}})
+				}
+			}
+		})
+
+		Convey("Groups that do not evolve on their own are merged", func() {
+			other, _ := NewWorld(40, 40)
+			otherPlacer := NewLifePlacer(&other)
+			otherPlacer.Place(golden("pulsar"), NewCoord(5, 5))
+
+			// The pulsar split into the groups of cells touching each other
+			visited := make(map[Coord]bool)
+			objects := make([]censusObject, 0)
+
+			other.ForEachLiveCell(func(coord Coord) {
+				if visited[coord] {
+					return
+				}
+
+				visited[coord] = true
+				cells := []Coord{coord}
+
+				for i := 0; i < len(cells); i++ {
+					for _, n := range other.GetCellLiveNeighboursCoords(cells[i]) {
+						if !visited[n] {
+							visited[n] = true
+							cells = append(cells, n)
+						}
+					}
+				}
+
+				objects = append(objects, classifyObject(cells, conway))
+			})
+
+			So(len(objects), ShouldEqual, 12)
+
+			merges, found := interactingObjects(objects, conway, 2)
+			So(found, ShouldBeTrue)
+			So(len(merges), ShouldEqual, 1)
+			So(len(merges[0]), ShouldEqual, 12)
+
+			_, found = interactingObjects(objects[:1], conway, 2)
+			So(found, ShouldBeFalse)
+		})
+
+		Convey("Counts each object by its canonical code", func() {
+			census, err := TakeCensus(&world, conway)
+			So(err, ShouldEqual, nil)
+
+			So(census, ShouldResemble, Census{
+				{Code: "xs4_33", Kind: StillLifeObject, Count: 2},
+				{Code: "xp15_4r4z4r4", Kind: OscillatorObject, Count: 1},
+				{Code: "xp2_318c", Kind: OscillatorObject, Count: 1},
+				{Code: "xp2_7", Kind: OscillatorObject, Count: 1},
+				{Code: "xq4_153", Kind: SpaceshipObject, Count: 1},
+				{Code: "xq4_6frc", Kind: SpaceshipObject, Count: 1},
+				{Code: "xs6_696", Kind: StillLifeObject, Count: 1},
+			})
+
+			So(census[0].Name(), ShouldEqual, "block")
+			So(strings.Split(census.String(), "\n")[1], ShouldEqual, "       2  xs4_33                   still life   block")
+		})
+
+		Convey("Whatever the phase and orientation of the objects", func() {
+			other, _ := NewWorld(40, 40)
+			otherPlacer := NewLifePlacer(&other)
+
+			otherPlacer.Place(Specie{{1}, {1}, {1}}, NewCoord(2, 2))
+			otherPlacer.Place(Specie{{1, 1, 1}, {1, 0, 0}, {0, 1, 0}}, NewCoord(20, 2))
+			otherPlacer.Place(Specie{{0, 1, 0}, {1, 0, 1}, {1, 0, 1}, {0, 1, 0}}, NewCoord(2, 20))
+
+			census, err := TakeCensus(&other, conway)
+			So(err, ShouldEqual, nil)
+			So(census, ShouldResemble, Census{
+				{Code: "xp2_7", Kind: OscillatorObject, Count: 1},
+				{Code: "xq4_153", Kind: SpaceshipObject, Count: 1},
+				{Code: "xs6_696", Kind: StillLifeObject, Count: 1},
+			})
+		})
+
+		Convey("Objects that do not settle on their own are unknown", func() {
+			other, _ := NewWorld(40, 40)
+			otherPlacer := NewLifePlacer(&other)
+			otherPlacer.Place(Specie{{0, 1, 1}, {1, 1, 0}, {0, 1, 0}}, NewCoord(10, 10))
+
+			census, err := TakeCensus(&other, conway)
+			So(err, ShouldEqual, nil)
+			So(len(census), ShouldEqual, 1)
+			So(census[0].Kind, ShouldEqual, UnknownObject)
+			So(census[0].Code, ShouldStartWith, "zz5_")
+		})
+
+		Convey("Of the cells in any engine", func() {
+			expected, _ := TakeCensus(&world, conway)
+
+			for _, name := range []string{"dense", "hashlife"} {
+				other := world.Copy()
+
+				if name == "hashlife" {
+					other = NewUnboundedWorld()
+
+					world.ForEachLiveCell(func(coord Coord) {
+						other.ActivateCell(coord)
+					})
+				}
+
+				engine, err := NewEngine(name, &other, conway)
+				So(err, ShouldEqual, nil)
+
+				census, err := TakeCensus(engine, conway)
+				So(err, ShouldEqual, nil)
+				So(census, ShouldResemble, expected)
+			}
+		})
+
+		Convey("Only on rules without dying states", func() {
+			brain, _ := ParseRule("B2/S/C3")
+			_, err := TakeCensus(&world, brain)
+			So(err, ShouldNotEqual, nil)
+		})
+	})
 }
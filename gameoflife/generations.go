@@ -0,0 +1,62 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rules where dying cells go through refractory states before being dead,
+// not counting as live neighbours meanwhile, as Brian's Brain, B2/S/C3
+type GenerationsRule struct {
+	Ruleset
+
+	// Including the dead and the live ones
+	NumberOfStates int
+}
+
+// The maximum number of states, as in other programs
+const maxGenerationsStates = 256
+
+// Understands B2/S/C3, the older S/B/C /2/3 and a G instead of C,
+// with the V or H suffixes of the neighbourhood either on the states or before
+func ParseGenerationsRule(rule string) (Ruleset, error) {
+	parts := strings.Split(strings.Replace(rule, " ", "", -1), "/")
+
+	if len(parts) != 3 {
+		return nil, errors.New(fmt.Sprintf("Invalid rule \"%s\"", rule))
+	}
+
+	if suffix := strings.TrimLeft(parts[2], "CcGg0123456789"); len(suffix) > 0 {
+		parts[1] += suffix
+		parts[2] = parts[2][:len(parts[2])-len(suffix)]
+	}
+
+	states, err := strconv.Atoi(strings.TrimLeft(parts[2], "CcGg"))
+
+	if err != nil || states < 2 || states > maxGenerationsStates {
+		return nil, errors.New(fmt.Sprintf("Invalid number of states in rule \"%s\"", rule))
+	}
+
+	ruleset, err := ParseRule(parts[0] + "/" + parts[1])
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid rule \"%s\"", rule))
+	}
+
+	// That's just a rule without dying cells
+	if states == 2 {
+		return ruleset, nil
+	}
+
+	return &GenerationsRule{ruleset, states}, nil
+}
+
+func (this *GenerationsRule) States() int {
+	return this.NumberOfStates
+}
+
+func (this *GenerationsRule) String() string {
+	return fmt.Sprintf("%s/C%d", this.Ruleset.String(), this.NumberOfStates)
+}
@@ -1,75 +1,96 @@
 package gameoflife
 
+const DefaultRule = "B3/S23"
+
 type Generator struct {
 	World *World
 	Rules []Rule
-}
 
-func CreateDefaultRules(world *World) []Rule {
-	matrix := world.GetActiveMatrix()
+	// Above 2, cells that die go through States - 2 dying states before being dead
+	States int
 
-	countLiveNeighbours := func(neighbours NeighboursCoords) int {
-		// NOTE: this is similar to reduce(sum)
-		count := 0
+	// Above 1, how many goroutines compute each generation, each one a stripe of rows
+	Workers int
 
-		for _, coord := range neighbours {
-			if matrix.IsLive(coord) {
-				count++
-			}
-		}
+	// How much work was done, and how much was skipped in stable parts of the world
+	Counters ActivityCounters
 
-		return count
-	}
+	// The tiles whose cells may change in the next step, all when nil
+	activeTiles map[Coord]bool
+}
 
-	return []Rule{
-		NewRule(func(coord Coord) bool {
-			// Applies to dead cells
-			return !matrix.IsLive(coord)
-		}, func(neighbours NeighboursCoords, coord Coord) bool {
-			return countLiveNeighbours(neighbours) == 3
-		}),
-
-		NewRule(func(coord Coord) bool {
-			// Applies to live cells
-			return matrix.IsLive(coord)
-		}, func(neighbours NeighboursCoords, coord Coord) bool {
-			nLiveNeighbours := countLiveNeighbours(neighbours)
-			return nLiveNeighbours == 2 || nLiveNeighbours == 3
-		}),
-	}
+func CreateDefaultRules(world *World) []Rule {
+	rules, _ := CreateRulesFromString(world, DefaultRule)
+	return rules
 }
 
 func NewGenericGenerator(world *World, rules []Rule) Generator {
-	return Generator{world, rules}
+	return Generator{World: world, Rules: rules, States: 2}
+}
+
+func NewRulesetGenerator(world *World, ruleset Ruleset) Generator {
+	world.SetNeighbourhood(ruleset.GetNeighbourhood())
+
+	return Generator{World: world, Rules: ruleset.CreateRules(world), States: ruleset.States()}
 }
 
 func NewGenerator(world *World) Generator {
 	return NewGenericGenerator(world, CreateDefaultRules(world))
 }
 
-func (this *Generator) Step() {
+// The next state of the cell, and its neighbours
+func (this *Generator) nextCell(coord Coord) (Cell, NeighboursCoords) {
 	activeMatrix := this.World.GetActiveMatrix()
-	inactiveMatrix := this.World.GetInactiveMatrix()
+	neighbours := this.World.GetCellNeighboursCoords(coord)
 
-	this.World.ForEachCoordinate(func(coord Coord) {
-		neighbours := this.World.GetCellNeighboursCoords(coord)
+	cell := activeMatrix.GetCell(coord)
+
+	// Dying cells just follow their way, regardless of the rules
+	if cell.IsDying() {
+		return cell.Decay(this.States), neighbours
+	}
 
-		for _, n := range neighbours {
-			if !activeMatrix.IsLive(n) && !inactiveMatrix.IsLive(n) {
-				inactiveMatrix.SetCellState(n, false)
+	for _, rule := range this.Rules {
+		if rule.Filter(coord) {
+			if rule.ApplyToCell(coord, neighbours) {
+				return LiveCell, neighbours
 			}
+
+			break
 		}
+	}
 
-		inactiveMatrix.SetCellState(coord, func() bool {
-			for _, rule := range this.Rules {
-				if rule.Filter(coord) {
-					return rule.ApplyToCell(coord, neighbours)
-				}
-			}
+	if cell.IsLive() {
+		return cell.Decay(this.States), neighbours
+	}
+
+	return DeadCell, neighbours
+}
+
+// Dead cells are only kept to be visited, next to live ones,
+// so that the matrix does not grow with what patterns leave behind
+func (this *Generator) isKept(coord Coord, next Cell) bool {
+	return next != DeadCell && !this.World.IsAbsorbed(coord)
+}
+
+func (this *Generator) Step() {
+	this.watchChanges()
+
+	if this.Workers > 1 {
+		this.stepInParallel()
+		return
+	}
+
+	tiles := make(map[Coord]bool)
+
+	this.World.ForEachCoordinate(func(coord Coord) {
+		if this.isStable(coord) {
+			this.skip(coord)
+			return
+		}
 
-			return false
-		}())
+		this.apply(this.evaluate(coord), tiles)
 	})
 
-	this.World.SwapMatrices()
+	this.finishStep(tiles)
 }
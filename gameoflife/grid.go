@@ -0,0 +1,75 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The shape of the cells, which changes how they are printed
+// and which neighbourhoods make sense
+type Grid string
+
+const (
+	SquareGrid Grid = "square"
+
+	// In axial coordinates: each row is half a cell to the left of the one
+	// above it, so that the cells above are the north-west and north ones
+	HexagonalGrid Grid = "hexagonal"
+
+	// Triangles alternate pointing up and down, the one at the origin pointing up
+	TriangularGrid Grid = "triangular"
+)
+
+var grids = []Grid{
+	SquareGrid,
+	HexagonalGrid,
+	TriangularGrid,
+}
+
+func IsUpTriangle(coord Coord) bool {
+	x, y := coord.Get()
+	return (x+y)&1 == 0
+}
+
+// An empty name means the square grid
+func ParseGrid(name string) (Grid, error) {
+	if name == "" {
+		return SquareGrid, nil
+	}
+
+	for _, grid := range grids {
+		if string(grid) == name {
+			return grid, nil
+		}
+	}
+
+	return "", errors.New(fmt.Sprintf("Unknown grid \"%s\"", name))
+}
+
+// The neighbourhood cells have unless the rule says otherwise
+func (this Grid) DefaultNeighbourhood() Neighbourhood {
+	if this == HexagonalGrid {
+		return Neighbourhood{Type: HexagonalNeighbourhood, Range: 1}
+	}
+
+	if this == TriangularGrid {
+		return Neighbourhood{Type: TriangularNeighbourhood, Range: 1}
+	}
+
+	return DefaultNeighbourhood()
+}
+
+// Whether a ruleset can run on the grid. Custom neighbourhoods are
+// trusted to have been made for it
+func (this Grid) Supports(ruleset Ruleset) bool {
+	neighbourhood := ruleset.GetNeighbourhood()
+
+	switch this {
+	case HexagonalGrid:
+		return neighbourhood.Type == HexagonalNeighbourhood || neighbourhood.Type == CustomNeighbourhood
+	case TriangularGrid:
+		return neighbourhood.IsTriangular() || neighbourhood.Type == CustomNeighbourhood
+	}
+
+	return true
+}
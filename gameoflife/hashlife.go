@@ -0,0 +1,683 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// A square of 2^level cells on each side, made of four squares half as
+// big. Equal squares are the same node, so a pattern that repeats itself
+// is stored only once
+type quadNode struct {
+	nw, ne, sw, se *quadNode
+	level          uint
+	population     uint64
+}
+
+type quadKey struct {
+	nw, ne, sw, se *quadNode
+}
+
+// A node and a step of 2^stepPow2 generations, 2^(level-2) at most
+type resultKey struct {
+	node     *quadNode
+	stepPow2 uint
+}
+
+// The cache of the nodes, making sure each square exists only once
+type quadtree struct {
+	nodes map[quadKey]*quadNode
+
+	// The dead and the live cell
+	leaves [2]*quadNode
+
+	// Empty nodes, by level
+	empty []*quadNode
+
+	// The centre of each node, 2^(level-1) cells on each side, some generations
+	// later, so that steps of any size share the nodes computed before
+	results map[resultKey]*quadNode
+}
+
+// Roughly how much memory a node takes, counting its entry in the cache
+const quadNodeBytes = 128
+
+const DefaultHashLifeMemoryBudget = 512 << 20
+
+// Coordinates would not fit in an int beyond that
+const maxHashLifeStepPow2 = 48
+
+func newQuadtree() quadtree {
+	tree := quadtree{nodes: make(map[quadKey]*quadNode), results: make(map[resultKey]*quadNode)}
+	tree.leaves = [2]*quadNode{{}, {population: 1}}
+	tree.empty = []*quadNode{tree.leaves[0]}
+
+	return tree
+}
+
+func (this *quadtree) join(nw, ne, sw, se *quadNode) *quadNode {
+	key := quadKey{nw, ne, sw, se}
+
+	if node, found := this.nodes[key]; found {
+		return node
+	}
+
+	node := &quadNode{nw: nw, ne: ne, sw: sw, se: se, level: nw.level + 1, population: nw.population + ne.population + sw.population + se.population}
+	this.nodes[key] = node
+
+	return node
+}
+
+func (this *quadtree) emptyNode(level uint) *quadNode {
+	for uint(len(this.empty)) <= level {
+		e := this.empty[len(this.empty)-1]
+		this.empty = append(this.empty, this.join(e, e, e, e))
+	}
+
+	return this.empty[level]
+}
+
+// The node with the cell at x, y, relative to its top left corner, made live or dead
+func (this *quadtree) setCell(node *quadNode, x, y int, live bool) *quadNode {
+	if node.level == 0 {
+		if live {
+			return this.leaves[1]
+		}
+
+		return this.leaves[0]
+	}
+
+	half := 1 << (node.level - 1)
+
+	nw, ne, sw, se := node.nw, node.ne, node.sw, node.se
+
+	switch {
+	case x < half && y < half:
+		nw = this.setCell(nw, x, y, live)
+	case y < half:
+		ne = this.setCell(ne, x-half, y, live)
+	case x < half:
+		sw = this.setCell(sw, x, y-half, live)
+	default:
+		se = this.setCell(se, x-half, y-half, live)
+	}
+
+	return this.join(nw, ne, sw, se)
+}
+
+// The smallest node, 8 cells on each side at least, with all the given cells,
+// relative to the returned top left corner
+func (this *quadtree) fromCoords(cells []Coord) (*quadNode, Coord) {
+	if len(cells) == 0 {
+		return this.emptyNode(3), NewCoord(0, 0)
+	}
+
+	minX, minY := cells[0].Get()
+	maxX, maxY := minX, minY
+
+	for _, cell := range cells {
+		x, y := cell.Get()
+
+		if x < minX {
+			minX = x
+		}
+
+		if x > maxX {
+			maxX = x
+		}
+
+		if y < minY {
+			minY = y
+		}
+
+		if y > maxY {
+			maxY = y
+		}
+	}
+
+	level := uint(3)
+
+	for 1<<level <= maxX-minX || 1<<level <= maxY-minY {
+		level++
+	}
+
+	node := this.emptyNode(level)
+
+	for _, cell := range cells {
+		x, y := cell.Get()
+		node = this.setCell(node, x-minX, y-minY, true)
+	}
+
+	return node, NewCoord(minX, minY)
+}
+
+// Whether the cell at x, y, relative to the top left corner of the node, is live
+func (this *quadtree) isLive(node *quadNode, x, y int) bool {
+	for node.level > 0 && node.population > 0 {
+		half := 1 << (node.level - 1)
+
+		switch {
+		case x < half && y < half:
+			node = node.nw
+		case y < half:
+			node, x = node.ne, x-half
+		case x < half:
+			node, y = node.sw, y-half
+		default:
+			node, x, y = node.se, x-half, y-half
+		}
+	}
+
+	return node.population > 0
+}
+
+// Calls f with each live cell, the top left corner of the node being at origin
+func (this *quadtree) forEachLiveCell(node *quadNode, origin Coord, f func(Coord)) {
+	if node.population == 0 {
+		return
+	}
+
+	if node.level == 0 {
+		f(origin)
+		return
+	}
+
+	x, y := origin.Get()
+	half := 1 << (node.level - 1)
+
+	this.forEachLiveCell(node.nw, NewCoord(x, y), f)
+	this.forEachLiveCell(node.ne, NewCoord(x+half, y), f)
+	this.forEachLiveCell(node.sw, NewCoord(x, y+half), f)
+	this.forEachLiveCell(node.se, NewCoord(x+half, y+half), f)
+}
+
+// The node twice as big, with this one in its centre
+func (this *quadtree) expand(node *quadNode) *quadNode {
+	e := this.emptyNode(node.level - 1)
+
+	return this.join(
+		this.join(e, e, e, node.nw),
+		this.join(e, e, node.ne, e),
+		this.join(e, node.sw, e, e),
+		this.join(node.se, e, e, e))
+}
+
+func (this *quadtree) centre(node *quadNode) *quadNode {
+	return this.join(node.nw.se, node.ne.sw, node.sw.ne, node.se.nw)
+}
+
+type windowKey struct {
+	nw, ne, sw, se *quadNode
+	x, y           int
+}
+
+// The node as big as each of the four ones, whose top left corner is at x, y
+// in the square they make, 0 <= x, y < 2^level. Squares seen before are in the cache
+func (this *quadtree) window(nw, ne, sw, se *quadNode, x, y int, cache map[windowKey]*quadNode) *quadNode {
+	if x == 0 && y == 0 {
+		return nw
+	}
+
+	if nw.population+ne.population+sw.population+se.population == 0 {
+		return this.emptyNode(nw.level)
+	}
+
+	key := windowKey{nw, ne, sw, se, x, y}
+
+	if node, found := cache[key]; found {
+		return node
+	}
+
+	half := 1 << (nw.level - 1)
+
+	grid := [4][4]*quadNode{
+		{nw.nw, nw.ne, ne.nw, ne.ne},
+		{nw.sw, nw.se, ne.sw, ne.se},
+		{sw.nw, sw.ne, se.nw, se.ne},
+		{sw.sw, sw.se, se.sw, se.se},
+	}
+
+	i, j := y/half, x/half
+
+	quarter := func(a, b int) *quadNode {
+		return this.window(grid[i+a][j+b], grid[i+a][j+b+1], grid[i+a+1][j+b], grid[i+a+1][j+b+1], x%half, y%half, cache)
+	}
+
+	node := this.join(quarter(0, 0), quarter(0, 1), quarter(1, 0), quarter(1, 1))
+	cache[key] = node
+
+	return node
+}
+
+// A node of the given level with this one, which must fit in it, at x, y from its top left corner
+func (this *quadtree) placeAt(node *quadNode, level uint, x, y int) *quadNode {
+	for node.level < level {
+		e := this.emptyNode(node.level)
+		node = this.join(node, e, e, e)
+	}
+
+	if x == 0 && y == 0 {
+		return node
+	}
+
+	// The node in the square twice as big whose window is the one wanted
+	size := 1 << level
+	e := this.emptyNode(level)
+	nw, ne, sw, se := e, e, e, e
+
+	switch {
+	case x == 0:
+		sw, y = node, size-y
+	case y == 0:
+		ne, x = node, size-x
+	default:
+		se, x, y = node, size-x, size-y
+	}
+
+	return this.window(nw, ne, sw, se, x, y, make(map[windowKey]*quadNode))
+}
+
+// The live cells of both nodes, of the same level
+func (this *quadtree) union(a, b *quadNode) *quadNode {
+	switch {
+	case a.population == 0:
+		return b
+	case b.population == 0 || a == b:
+		return a
+	case a.level == 0:
+		return this.leaves[1]
+	}
+
+	return this.join(this.union(a.nw, b.nw), this.union(a.ne, b.ne), this.union(a.sw, b.sw), this.union(a.se, b.se))
+}
+
+// The top left and bottom right corners of the live cells, relative to the top left corner of the node
+func (this *quadtree) bounds(node *quadNode) (topLeft, bottomRight Coord, found bool) {
+	cache := make(map[*quadNode][2]Coord)
+
+	var bounds func(node *quadNode) [2]Coord
+
+	bounds = func(node *quadNode) [2]Coord {
+		if node.level == 0 {
+			return [2]Coord{NewCoord(0, 0), NewCoord(0, 0)}
+		}
+
+		if b, found := cache[node]; found {
+			return b
+		}
+
+		half := 1 << (node.level - 1)
+		first := true
+		var result [2]Coord
+
+		for i, child := range [4]*quadNode{node.nw, node.ne, node.sw, node.se} {
+			if child.population == 0 {
+				continue
+			}
+
+			dx, dy := (i%2)*half, (i/2)*half
+			b := bounds(child)
+			topLeft, bottomRight := NewCoord(b[0][0]+dx, b[0][1]+dy), NewCoord(b[1][0]+dx, b[1][1]+dy)
+
+			if first {
+				result, first = [2]Coord{topLeft, bottomRight}, false
+				continue
+			}
+
+			if topLeft[0] < result[0][0] {
+				result[0][0] = topLeft[0]
+			}
+
+			if topLeft[1] < result[0][1] {
+				result[0][1] = topLeft[1]
+			}
+
+			if bottomRight[0] > result[1][0] {
+				result[1][0] = bottomRight[0]
+			}
+
+			if bottomRight[1] > result[1][1] {
+				result[1][1] = bottomRight[1]
+			}
+		}
+
+		cache[node] = result
+
+		return result
+	}
+
+	if node.population == 0 {
+		return NewCoord(0, 0), NewCoord(0, 0), false
+	}
+
+	b := bounds(node)
+
+	return b[0], b[1], true
+}
+
+// Forgets the nodes that are not part of any of the roots,
+// and the results that were forgotten
+func (this *quadtree) collect(roots ...*quadNode) {
+	kept := make(map[*quadNode]bool)
+
+	var mark func(node *quadNode)
+
+	mark = func(node *quadNode) {
+		if node.level == 0 || kept[node] {
+			return
+		}
+
+		kept[node] = true
+
+		mark(node.nw)
+		mark(node.ne)
+		mark(node.sw)
+		mark(node.se)
+	}
+
+	for _, root := range append(roots, this.empty...) {
+		mark(root)
+	}
+
+	for key, node := range this.nodes {
+		if !kept[node] {
+			delete(this.nodes, key)
+		}
+	}
+
+	for key, result := range this.results {
+		if !kept[key.node] || !kept[result] {
+			delete(this.results, key)
+		}
+	}
+}
+
+// How much memory the nodes and their results take, roughly
+func (this *quadtree) size() uint64 {
+	return uint64(len(this.nodes)+len(this.results)) * quadNodeBytes
+}
+
+// Runs life-like rules on huge patterns and for many generations at once,
+// remembering how each square of cells evolves, so that squares seen before
+// are not computed again. The universe has no edges, so it only runs on
+// unbounded worlds, which are only updated by Sync
+type HashLifeGenerator struct {
+	World *World
+	Rule  *LifeLikeRule
+
+	// How many generations have passed
+	Generation uint64
+
+	// Nodes are forgotten when the cache takes more memory than that, in bytes
+	MemoryBudget uint64
+
+	tree quadtree
+	root *quadNode
+
+	// Where the top left corner of the root is
+	origin Coord
+}
+
+func newHashLifeRule(ruleset Ruleset) (*LifeLikeRule, error) {
+	rule, isLifeLike := ruleset.(*LifeLikeRule)
+
+	if !isLifeLike || rule.VonNeumann || rule.Hexagonal || rule.Triangular != "" {
+		return nil, errors.New(fmt.Sprintf("The HashLife engine only supports life-like rules on the Moore neighbourhood, not %s", ruleset))
+	}
+
+	return rule, nil
+}
+
+func NewHashLifeGenerator(world *World, ruleset Ruleset) (HashLifeGenerator, error) {
+	rule, err := newHashLifeRule(ruleset)
+
+	if err != nil {
+		return HashLifeGenerator{}, err
+	}
+
+	if !world.Unbounded || world.Grid != SquareGrid {
+		return HashLifeGenerator{}, errors.New("The HashLife engine only runs on unbounded square worlds")
+	}
+
+	cells := make([]Coord, 0)
+
+	world.ForEachCoordinate(func(coord Coord) {
+		if world.ActiveMatrix.IsLive(coord) {
+			cells = append(cells, coord)
+		}
+	})
+
+	tree := newQuadtree()
+	root, origin := tree.fromCoords(cells)
+
+	return HashLifeGenerator{World: world, Rule: rule, MemoryBudget: DefaultHashLifeMemoryBudget, tree: tree, root: root, origin: origin}, nil
+}
+
+func (this *HashLifeGenerator) Population() uint64 {
+	return this.root.population
+}
+
+// The next generation of the 2x2 cells in the centre of a 4x4 node
+func (this *HashLifeGenerator) baseResult(node *quadNode) *quadNode {
+	var cells [4][4]bool
+
+	this.tree.forEachLiveCell(node, NewCoord(0, 0), func(coord Coord) {
+		cells[coord[1]][coord[0]] = true
+	})
+
+	next := func(x, y int) *quadNode {
+		count := 0
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if (dx != 0 || dy != 0) && cells[y+dy][x+dx] {
+					count++
+				}
+			}
+		}
+
+		if cells[y][x] && this.Rule.Survival[count] || !cells[y][x] && this.Rule.Birth[count] {
+			return this.tree.leaves[1]
+		}
+
+		return this.tree.leaves[0]
+	}
+
+	return this.tree.join(next(1, 1), next(2, 1), next(1, 2), next(2, 2))
+}
+
+// The centre of the node 2^stepPow2 generations later, or 2^(level-2)
+// for the nodes too small for that
+func (this *HashLifeGenerator) result(node *quadNode, stepPow2 uint) *quadNode {
+	if stepPow2 > node.level-2 {
+		stepPow2 = node.level - 2
+	}
+
+	key := resultKey{node, stepPow2}
+
+	if result, found := this.tree.results[key]; found {
+		return result
+	}
+
+	t := &this.tree
+
+	var result *quadNode
+
+	switch {
+	case node.population == 0:
+		result = t.emptyNode(node.level - 1)
+	case node.level == 2:
+		result = this.baseResult(node)
+	default:
+		nw, ne, sw, se := node.nw, node.ne, node.sw, node.se
+
+		// The 9 overlapping squares half as big as the node, each
+		// one a quarter of the node away from the next one
+		n00 := this.result(nw, stepPow2)
+		n01 := this.result(t.join(nw.ne, ne.nw, nw.se, ne.sw), stepPow2)
+		n02 := this.result(ne, stepPow2)
+		n10 := this.result(t.join(nw.sw, nw.se, sw.nw, sw.ne), stepPow2)
+		n11 := this.result(t.centre(node), stepPow2)
+		n12 := this.result(t.join(ne.sw, ne.se, se.nw, se.ne), stepPow2)
+		n20 := this.result(sw, stepPow2)
+		n21 := this.result(t.join(sw.ne, se.nw, sw.se, se.sw), stepPow2)
+		n22 := this.result(se, stepPow2)
+
+		quarters := [4]*quadNode{
+			t.join(n00, n01, n10, n11),
+			t.join(n01, n02, n11, n12),
+			t.join(n10, n11, n20, n21),
+			t.join(n11, n12, n21, n22),
+		}
+
+		// Steps smaller than the node allows are done only once
+		for i, quarter := range quarters {
+			if stepPow2 == node.level-2 {
+				quarters[i] = this.result(quarter, stepPow2)
+			} else {
+				quarters[i] = t.centre(quarter)
+			}
+		}
+
+		result = t.join(quarters[0], quarters[1], quarters[2], quarters[3])
+	}
+
+	t.results[key] = result
+
+	return result
+}
+
+// Whether all the live cells are in the middle of the root, half as big as it
+func (this *HashLifeGenerator) isCentred() bool {
+	root := this.root
+
+	if root.level < 3 {
+		return false
+	}
+
+	return root.nw.se.se.population+root.ne.sw.sw.population+root.sw.ne.ne.population+root.se.nw.nw.population == root.population
+}
+
+func (this *HashLifeGenerator) expand() {
+	half := 1 << (this.root.level - 1)
+	this.origin = NewCoord(this.origin[0]-half, this.origin[1]-half)
+	this.root = this.tree.expand(this.root)
+}
+
+// Moves 2^k generations ahead at once
+func (this *HashLifeGenerator) StepPow2(k uint) error {
+	if k > maxHashLifeStepPow2 {
+		return errors.New(fmt.Sprintf("Cannot step 2^%d generations at once, 2^%d at most", k, maxHashLifeStepPow2))
+	}
+
+	if this.root.population > 0 {
+		// Cells cannot move further than 2^k, so the result, the centre
+		// of the root, has all of them
+		for this.root.level < k+3 || !this.isCentred() {
+			this.expand()
+		}
+
+		quarter := 1 << (this.root.level - 2)
+		this.origin = NewCoord(this.origin[0]+quarter, this.origin[1]+quarter)
+		this.root = this.result(this.root, k)
+	}
+
+	this.Generation += 1 << k
+
+	if this.tree.size() > this.MemoryBudget {
+		this.tree.collect(this.root)
+	}
+
+	return nil
+}
+
+func (this *HashLifeGenerator) Step() {
+	this.StepPow2(0)
+}
+
+// Copies the cells into the world
+func (this *HashLifeGenerator) Sync() {
+	this.World.ActiveMatrix = CreateMatrix()
+	this.World.InactiveMatrix = CreateMatrix()
+
+	this.ForEachLiveCell(func(coord Coord) {
+		this.World.SetCell(coord, LiveCell)
+	})
+}
+
+// Moves n generations ahead in as few steps of a power of 2 as possible
+func (this *HashLifeGenerator) StepN(n uint64) {
+	for n != 0 {
+		k := uint(bits.Len64(n) - 1)
+
+		if k > maxHashLifeStepPow2 {
+			k = maxHashLifeStepPow2
+		}
+
+		this.StepPow2(k)
+		n -= 1 << k
+	}
+}
+
+// Sharing the quadtree, whose nodes never change, and the world, which
+// only Sync writes to
+func (this *HashLifeGenerator) Copy() Engine {
+	generator := *this
+	return &generator
+}
+
+func (this *HashLifeGenerator) Size() (h, w int) {
+	return 0, 0
+}
+
+func (this *HashLifeGenerator) GetGrid() Grid {
+	return SquareGrid
+}
+
+func (this *HashLifeGenerator) IsCoordValid(coord Coord) bool {
+	return true
+}
+
+// Whether the coordinate is in the root, and where, relative to its top left corner
+func (this *HashLifeGenerator) inRoot(coord Coord) (x, y int, inside bool) {
+	x, y = coord[0]-this.origin[0], coord[1]-this.origin[1]
+	size := 1 << this.root.level
+
+	return x, y, x >= 0 && x < size && y >= 0 && y < size
+}
+
+func (this *HashLifeGenerator) GetCell(coord Coord) (Cell, error) {
+	if x, y, inside := this.inRoot(coord); inside && this.tree.isLive(this.root, x, y) {
+		return LiveCell, nil
+	}
+
+	return DeadCell, nil
+}
+
+// Cells in any state other than dead are live
+func (this *HashLifeGenerator) SetCell(coord Coord, cell Cell) error {
+	x, y, inside := this.inRoot(coord)
+
+	for ; !inside; x, y, inside = this.inRoot(coord) {
+		this.expand()
+	}
+
+	this.root = this.tree.setCell(this.root, x, y, cell != DeadCell)
+
+	return nil
+}
+
+func (this *HashLifeGenerator) ForEachLiveCell(f func(Coord)) {
+	this.tree.forEachLiveCell(this.root, this.origin, f)
+}
+
+func (this *HashLifeGenerator) ForEachCell(f func(Coord, Cell)) {
+	this.ForEachLiveCell(func(coord Coord) {
+		f(coord, LiveCell)
+	})
+}
+
+func (this *HashLifeGenerator) Bounds() (topLeft, bottomRight Coord, found bool) {
+	return boundsOfLiveCells(this)
+}
@@ -0,0 +1,279 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The letters of each neighbour count, in the Hensel notation order
+var henselLetters = [9]string{
+	"",
+	"ce",
+	"cekain",
+	"cekainyqjr",
+	"cekainyqjrtwz",
+	"cekainyqjr",
+	"cekain",
+	"ce",
+	"",
+}
+
+// One configuration for each letter of neighbour counts up to 4, with
+// the neighbours clockwise from north-west, as in GetCellNeighbourhoodConfiguration.
+// The counts above 4 are the complements of the ones below it, with the same letters
+var henselConfigurations = [5]map[rune]string{
+	{},
+	{
+		'c': "10000000",
+		'e': "01000000",
+	},
+	{
+		'c': "10100000",
+		'e': "01010000",
+		'k': "10010000",
+		'a': "11000000",
+		'i': "01000100",
+		'n': "10001000",
+	},
+	{
+		'c': "10101000",
+		'e': "01010100",
+		'k': "01010010",
+		'a': "11000001",
+		'i': "10000011",
+		'n': "11010000",
+		'y': "10100100",
+		'q': "11000010",
+		'j': "11000100",
+		'r': "11001000",
+	},
+	{
+		'c': "10101010",
+		'e': "01010101",
+		'k': "11010010",
+		'a': "11110000",
+		'i': "10110001",
+		'n': "11001001",
+		'y': "11001010",
+		'q': "01110001",
+		'j': "11010100",
+		'r': "11101000",
+		't': "11100100",
+		'w': "11011000",
+		'z': "11001100",
+	},
+}
+
+type henselClass struct {
+	Count  int
+	Letter rune
+}
+
+// The class of each of the 256 possible configurations
+var henselClasses = func() [256]henselClass {
+	var classes [256]henselClass
+
+	position := func(configuration uint8, p int) bool {
+		return configuration&(0x80>>uint(p%8)) != 0
+	}
+
+	// Rotations are shifts of two positions, reflections mirror the positions as well
+	symmetries := func(configuration uint8) []uint8 {
+		result := make([]uint8, 0, 8)
+
+		for shift := 0; shift < 8; shift += 2 {
+			var rotated, reflected uint8
+
+			for p := 0; p < 8; p++ {
+				if position(configuration, p) {
+					rotated |= 0x80 >> uint((p+shift)%8)
+					reflected |= 0x80 >> uint((16-p+shift)%8)
+				}
+			}
+
+			result = append(result, rotated, reflected)
+		}
+
+		return result
+	}
+
+	for count, letters := range henselConfigurations {
+		for letter, bits := range letters {
+			var configuration uint8
+
+			for _, b := range bits {
+				configuration = configuration<<1 | uint8(b-'0')
+			}
+
+			for _, c := range symmetries(configuration) {
+				classes[c] = henselClass{count, letter}
+
+				if count < 4 {
+					classes[^c] = henselClass{8 - count, letter}
+				}
+			}
+		}
+	}
+
+	classes[0xff] = henselClass{8, 0}
+
+	return classes
+}()
+
+// Isotropic non-totalistic rules, where the shape of the live neighbours
+// matters, not only how many they are
+type IsotropicRule struct {
+	Birth, Survival [256]bool
+}
+
+// Reads transitions such as "2-a3cn4" into the set of configurations they allow
+func parseHenselTransitions(transitions string) ([256]bool, bool) {
+	var allowed [256]bool
+
+	for i := 0; i < len(transitions); {
+		c := transitions[i]
+
+		if c < '0' || c > '8' {
+			return allowed, false
+		}
+
+		count := int(c - '0')
+		i++
+
+		negated := i < len(transitions) && transitions[i] == '-'
+
+		if negated {
+			i++
+		}
+
+		letters := ""
+
+		for ; i < len(transitions) && (transitions[i] < '0' || transitions[i] > '8'); i++ {
+			if !strings.ContainsRune(henselLetters[count], rune(transitions[i])) {
+				return allowed, false
+			}
+
+			letters += string(transitions[i])
+		}
+
+		if negated && len(letters) == 0 {
+			return allowed, false
+		}
+
+		for configuration, class := range henselClasses {
+			if class.Count != count {
+				continue
+			}
+
+			listed := strings.ContainsRune(letters, class.Letter)
+
+			if len(letters) == 0 || listed != negated {
+				allowed[configuration] = true
+			}
+		}
+	}
+
+	return allowed, true
+}
+
+func ParseIsotropicRule(rule string) (IsotropicRule, error) {
+	invalid := errors.New(fmt.Sprintf("Invalid rule \"%s\"", rule))
+
+	// b and s are not Hensel letters, so they can be told apart even when lowercase
+	s := strings.ToLower(strings.Replace(rule, " ", "", -1))
+	s = strings.Replace(strings.Replace(s, "b", "B", -1), "s", "S", -1)
+
+	birth, survival, ok := splitBirthSurvival(s)
+
+	if !ok {
+		return IsotropicRule{}, invalid
+	}
+
+	result := IsotropicRule{}
+
+	var birthOk, survivalOk bool
+
+	result.Birth, birthOk = parseHenselTransitions(birth)
+	result.Survival, survivalOk = parseHenselTransitions(survival)
+
+	if !birthOk || !survivalOk {
+		return IsotropicRule{}, invalid
+	}
+
+	if result.Birth[0] {
+		return IsotropicRule{}, errors.New(fmt.Sprintf("Rules with birth on 0 neighbours, as \"%s\", are not supported", rule))
+	}
+
+	return result, nil
+}
+
+// Totalistic transitions are written just as the count, otherwise
+// the shortest between the letters and the negated letters is used
+func henselTransitionsToString(allowed [256]bool) string {
+	var output string
+
+	for count, letters := range henselLetters {
+		set, unset := "", ""
+
+		for _, letter := range letters {
+			for configuration, class := range henselClasses {
+				if class.Count == count && class.Letter == letter {
+					if allowed[configuration] {
+						set += string(letter)
+					} else {
+						unset += string(letter)
+					}
+
+					break
+				}
+			}
+		}
+
+		all := allowed[0x00] && count == 0 || allowed[0xff] && count == 8
+
+		switch {
+		case len(letters) == 0 && all, len(letters) > 0 && len(unset) == 0:
+			output += fmt.Sprintf("%d", count)
+		case len(set) == 0:
+		case len(set) <= len(unset):
+			output += fmt.Sprintf("%d%s", count, set)
+		default:
+			output += fmt.Sprintf("%d-%s", count, unset)
+		}
+	}
+
+	return output
+}
+
+func (this *IsotropicRule) String() string {
+	return "B" + henselTransitionsToString(this.Birth) + "/S" + henselTransitionsToString(this.Survival)
+}
+
+func (this *IsotropicRule) States() int {
+	return 2
+}
+
+func (this *IsotropicRule) GetNeighbourhood() Neighbourhood {
+	return DefaultNeighbourhood()
+}
+
+func (this *IsotropicRule) CreateRules(world *World) []Rule {
+	matrix := world.GetActiveMatrix()
+
+	return []Rule{
+		NewRule(func(coord Coord) bool {
+			// Applies to dead cells
+			return !matrix.IsLive(coord)
+		}, func(neighbours NeighboursCoords, coord Coord) bool {
+			return this.Birth[world.GetCellNeighbourhoodConfiguration(coord)]
+		}),
+
+		NewRule(func(coord Coord) bool {
+			// Applies to live cells
+			return matrix.IsLive(coord)
+		}, func(neighbours NeighboursCoords, coord Coord) bool {
+			return this.Survival[world.GetCellNeighbourhoodConfiguration(coord)]
+		}),
+	}
+}
@@ -13,8 +13,54 @@ func NewSpecieImporter() Importer {
 	return Importer{}
 }
 
+func importError(line, column int, format string, args ...interface{}) error {
+	return errors.New(fmt.Sprintf("%s on line %d, column %d", fmt.Sprintf(format, args...), line, column))
+}
+
+// Builds the smallest specie containing all the given live cells
+func specieFromCoords(cells []Coord) (Specie, error) {
+	if len(cells) == 0 {
+		return Specie{}, errors.New("Invalid specie")
+	}
+
+	minX, minY := cells[0].Get()
+	maxX, maxY := minX, minY
+
+	for _, cell := range cells {
+		x, y := cell.Get()
+
+		if x < minX {
+			minX = x
+		}
+
+		if x > maxX {
+			maxX = x
+		}
+
+		if y < minY {
+			minY = y
+		}
+
+		if y > maxY {
+			maxY = y
+		}
+	}
+
+	rows := make([][]int, maxY-minY+1)
+
+	for i := range rows {
+		rows[i] = make([]int, maxX-minX+1)
+	}
+
+	for _, cell := range cells {
+		x, y := cell.Get()
+		rows[y-minY][x-minX] = 1
+	}
+
+	return NewSpecie(rows)
+}
+
 // FIXME: this method is enoooormous and MUST be refactored
-// It also should somehow support Life 1.06
 func (this *Importer) ImportFromString(content string) (Specie, error) {
 	charToSpecieCellState := func(c rune, line int) (int, error) {
 		if c == '*' {
@@ -55,8 +101,8 @@ func (this *Importer) ImportFromString(content string) (Specie, error) {
 		for _, line := range splitted {
 			trimmed := strings.Trim(line, " ")
 
-			// Ignore comments and empty lines
-			if len(trimmed) == 0 || trimmed[0] == '#' {
+			// Ignore comments (also the .cells ones) and empty lines
+			if len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!' {
 				continue
 			}
 
@@ -100,3 +146,41 @@ func (this *Importer) ImportFromString(content string) (Specie, error) {
 
 	return NewSpecie(specieRows)
 }
+
+// Plaintext (.cells) files describe the pattern on "!Key: value" comments
+func (this *Importer) ImportFromPlaintextString(content string) (Pattern, error) {
+	specie, err := this.ImportFromString(content)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	pattern := NewPattern(specie)
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if len(trimmed) > 0 && trimmed[0] == '!' {
+			pattern.addComment(trimmed[1:])
+		}
+	}
+
+	return pattern, nil
+}
+
+func (this *Importer) Import(format PatternFormat, content string) (Pattern, error) {
+	switch format {
+	case PlaintextFormat:
+		return this.ImportFromPlaintextString(content)
+	case Life105Format:
+		return this.ImportFromLife105String(content)
+	case Life106Format:
+		return this.ImportFromLife106String(content)
+	case RLEFormat:
+		return this.ImportFromRLEString(content)
+	case MacrocellFormat:
+		return this.ImportFromMacrocellString(content)
+	}
+
+	return Pattern{}, errors.New(fmt.Sprintf("Importing %s patterns is not supported", format))
+}
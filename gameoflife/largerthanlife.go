@@ -0,0 +1,234 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An inclusive interval of neighbour counts, as 34..58
+type CountRange struct {
+	Min, Max int
+}
+
+func (this *CountRange) Contains(count int) bool {
+	return count >= this.Min && count <= this.Max
+}
+
+func (this CountRange) String() string {
+	return fmt.Sprintf("%d..%d", this.Min, this.Max)
+}
+
+// Totalistic rules over range-r neighbourhoods, as Bugs, R5,C0,M1,S34..58,B34..45,NM
+type LargerThanLifeRule struct {
+	Neighbourhood Neighbourhood
+
+	// As in the Generations rules, 2 when there are no dying cells
+	NumberOfStates int
+
+	// Whether the cell itself is counted as one of its neighbours
+	Middle bool
+
+	Survival, Birth []CountRange
+}
+
+func parseCountRange(s string) (CountRange, bool) {
+	limits := strings.Split(s, "..")
+
+	if len(limits) > 2 {
+		return CountRange{}, false
+	}
+
+	min, errMin := strconv.Atoi(limits[0])
+	max, errMax := min, error(nil)
+
+	if len(limits) == 2 {
+		max, errMax = strconv.Atoi(limits[1])
+	}
+
+	if errMin != nil || errMax != nil || min < 0 || min > max {
+		return CountRange{}, false
+	}
+
+	return CountRange{min, max}, true
+}
+
+// Understands the Golly notation, where C0 and C2 mean two states, M defaults to 0,
+// N to the Moore neighbourhood and S and B can have several ranges, as S2..3,5..6
+func ParseLargerThanLifeRule(rule string) (LargerThanLifeRule, error) {
+	invalid := errors.New(fmt.Sprintf("Invalid rule \"%s\"", rule))
+
+	s := strings.ToUpper(strings.Replace(rule, " ", "", -1))
+
+	result := LargerThanLifeRule{Neighbourhood: Neighbourhood{Type: MooreNeighbourhood}, NumberOfStates: 2}
+
+	// Where further count ranges go, after an S or a B
+	var ranges *[]CountRange
+
+	hasSurvival, hasBirth := false, false
+
+	for _, item := range strings.Split(s, ",") {
+		if len(item) == 0 {
+			return LargerThanLifeRule{}, invalid
+		}
+
+		key, value := item[0], item[1:]
+
+		if key >= '0' && key <= '9' {
+			r, ok := parseCountRange(item)
+
+			if ranges == nil || !ok {
+				return LargerThanLifeRule{}, invalid
+			}
+
+			*ranges = append(*ranges, r)
+			continue
+		}
+
+		ranges = nil
+
+		switch key {
+		case 'R':
+			r, err := strconv.Atoi(value)
+
+			if err != nil || r < 1 || r > maxNeighbourhoodRange {
+				return LargerThanLifeRule{}, invalid
+			}
+
+			result.Neighbourhood.Range = r
+		case 'C':
+			states, err := strconv.Atoi(value)
+
+			if err != nil || states < 0 || states == 1 || states > maxGenerationsStates {
+				return LargerThanLifeRule{}, errors.New(fmt.Sprintf("Invalid number of states in rule \"%s\"", rule))
+			}
+
+			if states > 2 {
+				result.NumberOfStates = states
+			}
+		case 'M':
+			if value != "0" && value != "1" {
+				return LargerThanLifeRule{}, invalid
+			}
+
+			result.Middle = value == "1"
+		case 'N':
+			result.Neighbourhood.Type = NeighbourhoodType(value)
+		case 'S', 'B':
+			r, ok := parseCountRange(value)
+
+			if !ok {
+				return LargerThanLifeRule{}, invalid
+			}
+
+			if key == 'S' {
+				ranges, hasSurvival = &result.Survival, true
+			} else {
+				ranges, hasBirth = &result.Birth, true
+			}
+
+			*ranges = append(*ranges, r)
+		default:
+			return LargerThanLifeRule{}, invalid
+		}
+	}
+
+	if result.Neighbourhood.Range == 0 || !hasSurvival || !hasBirth {
+		return LargerThanLifeRule{}, invalid
+	}
+
+	if _, err := NewNeighbourhood(result.Neighbourhood.Type, result.Neighbourhood.Range); err != nil {
+		return LargerThanLifeRule{}, err
+	}
+
+	maxCount := result.Neighbourhood.Size()
+
+	if result.Middle {
+		maxCount++
+	}
+
+	for _, r := range append(append([]CountRange{}, result.Survival...), result.Birth...) {
+		if r.Max > maxCount {
+			return LargerThanLifeRule{}, invalid
+		}
+	}
+
+	for _, r := range result.Birth {
+		// Only cells around live ones are ever evaluated
+		if r.Min == 0 {
+			return LargerThanLifeRule{}, errors.New(fmt.Sprintf("Rules with birth on 0 neighbours, as \"%s\", are not supported", rule))
+		}
+	}
+
+	return result, nil
+}
+
+func countRangesToString(ranges []CountRange) string {
+	s := make([]string, len(ranges))
+
+	for i, r := range ranges {
+		s[i] = r.String()
+	}
+
+	return strings.Join(s, ",")
+}
+
+func (this *LargerThanLifeRule) String() string {
+	states, middle := this.NumberOfStates, 0
+
+	if states == 2 {
+		states = 0
+	}
+
+	if this.Middle {
+		middle = 1
+	}
+
+	return fmt.Sprintf("R%d,C%d,M%d,S%s,B%s,N%s", this.Neighbourhood.Range, states, middle,
+		countRangesToString(this.Survival), countRangesToString(this.Birth), this.Neighbourhood.Type)
+}
+
+func (this *LargerThanLifeRule) States() int {
+	return this.NumberOfStates
+}
+
+func (this *LargerThanLifeRule) GetNeighbourhood() Neighbourhood {
+	return this.Neighbourhood
+}
+
+func (this *LargerThanLifeRule) CreateRules(world *World) []Rule {
+	matrix := world.GetActiveMatrix()
+
+	inRanges := func(ranges []CountRange, coord Coord) bool {
+		count := world.CountLiveNeighbours(coord)
+
+		if this.Middle && matrix.IsLive(coord) {
+			count++
+		}
+
+		for _, r := range ranges {
+			if r.Contains(count) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return []Rule{
+		NewRule(func(coord Coord) bool {
+			// Applies to dead cells
+			return !matrix.IsLive(coord)
+		}, func(neighbours NeighboursCoords, coord Coord) bool {
+			return inRanges(this.Birth, coord)
+		}),
+
+		NewRule(func(coord Coord) bool {
+			// Applies to live cells
+			return matrix.IsLive(coord)
+		}, func(neighbours NeighboursCoords, coord Coord) bool {
+			return inRanges(this.Survival, coord)
+		}),
+	}
+}
@@ -0,0 +1,118 @@
+package gameoflife
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// In Life 1.05 "#R 23/3" means survival on 2 or 3 neighbours and birth on 3
+func parseLife105Rule(rule string) (string, bool) {
+	s := strings.Split(rule, "/")
+
+	if len(s) != 2 {
+		return "", false
+	}
+
+	for _, part := range s {
+		for _, c := range part {
+			if c < '0' || c > '8' {
+				return "", false
+			}
+		}
+	}
+
+	return "B" + s[1] + "/S" + s[0], true
+}
+
+func (this *Importer) ImportFromLife105String(content string) (Pattern, error) {
+	lines := strings.Split(content, "\n")
+
+	if strings.TrimSpace(lines[0]) != "#Life 1.05" {
+		return Pattern{}, errors.New("Missing \"#Life 1.05\" header")
+	}
+
+	pattern := NewPattern(Specie{})
+
+	cells := make([]Coord, 0)
+
+	// Rows without a preceding #P are placed at the origin
+	originX, row := 0, 0
+
+	for index, line := range lines[1:] {
+		// the header is on the first line
+		lineNumber := index + 2
+
+		trimmed := strings.TrimSpace(line)
+
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#D") {
+			pattern.addComment(trimmed[2:])
+			continue
+		}
+
+		if trimmed == "#N" {
+			pattern.Rule = "B3/S23"
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#R") {
+			rule, ok := parseLife105Rule(strings.TrimSpace(trimmed[2:]))
+
+			if !ok {
+				return Pattern{}, importError(lineNumber, 1, "Invalid rule \"%s\"", strings.TrimSpace(trimmed[2:]))
+			}
+
+			pattern.Rule = rule
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#P") {
+			fields := strings.Fields(trimmed[2:])
+
+			if len(fields) != 2 {
+				return Pattern{}, importError(lineNumber, 1, "Expected \"#P x y\" but found \"%s\"", trimmed)
+			}
+
+			x, errX := strconv.Atoi(fields[0])
+			y, errY := strconv.Atoi(fields[1])
+
+			if errX != nil || errY != nil {
+				return Pattern{}, importError(lineNumber, 1, "Invalid block position \"%s\"", trimmed)
+			}
+
+			originX, row = x, y
+			continue
+		}
+
+		if trimmed[0] == '#' {
+			// Unknown line types are ignored, as other readers do
+			continue
+		}
+
+		for column, c := range trimmed {
+			switch c {
+			case '*', 'O':
+				cells = append(cells, NewCoord(originX+column, row))
+			case '.':
+			default:
+				return Pattern{}, importError(lineNumber, strings.Index(line, trimmed)+column+1, "Invalid char \"%c\"", c)
+			}
+		}
+
+		row++
+	}
+
+	specie, err := specieFromCoords(cells)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	pattern.Specie = specie
+
+	return pattern, nil
+}
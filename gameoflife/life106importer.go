@@ -0,0 +1,70 @@
+package gameoflife
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+func (this *Importer) ImportFromLife106String(content string) (Pattern, error) {
+	lines := strings.Split(content, "\n")
+
+	if strings.TrimSpace(lines[0]) != "#Life 1.06" {
+		return Pattern{}, errors.New("Missing \"#Life 1.06\" header")
+	}
+
+	pattern := NewPattern(Specie{})
+
+	cells := make([]Coord, 0)
+
+	for index, line := range lines[1:] {
+		// the header is on the first line
+		lineNumber := index + 2
+
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "#D") {
+			pattern.addComment(trimmed[2:])
+			continue
+		}
+
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+
+		if len(fields) != 2 {
+			return Pattern{}, importError(lineNumber, 1, "Expected a \"x y\" pair but found \"%s\"", trimmed)
+		}
+
+		var coord Coord
+
+		column := 0
+
+		for i, field := range fields {
+			column += strings.Index(line[column:], field)
+
+			n, err := strconv.Atoi(field)
+
+			if err != nil {
+				return Pattern{}, importError(lineNumber, column+1, "Invalid coordinate \"%s\"", field)
+			}
+
+			coord[i] = n
+			column += len(field)
+		}
+
+		cells = append(cells, coord)
+	}
+
+	specie, err := specieFromCoords(cells)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	pattern.Specie = specie
+
+	return pattern, nil
+}
@@ -3,18 +3,18 @@ package gameoflife
 import "errors"
 
 type LifePlacer struct {
-	World *World
+	Universe Universe
 }
 
-func NewLifePlacer(world *World) LifePlacer {
-	return LifePlacer{world}
+func NewLifePlacer(universe Universe) LifePlacer {
+	return LifePlacer{universe}
 }
 
 func (this *LifePlacer) Place(specie Specie, coord Coord) error {
 	specieH, specieW := specie.Size()
 	x, y := coord.Get()
 
-	if !this.World.IsCoordValid(coord) || !this.World.IsCoordValid(NewCoord(x+specieW-1, y+specieH-1)) {
+	if !this.Universe.IsCoordValid(coord) || !this.Universe.IsCoordValid(NewCoord(x+specieW-1, y+specieH-1)) {
 		return errors.New("Invalid position to form of life")
 	}
 
@@ -24,7 +24,7 @@ func (this *LifePlacer) Place(specie Specie, coord Coord) error {
 				continue
 			}
 
-			if err := this.World.ActivateCell(NewCoord(x+itW, y+itH)); err != nil {
+			if err := this.Universe.SetCell(NewCoord(x+itW, y+itH), LiveCell); err != nil {
 				return err
 			}
 		}
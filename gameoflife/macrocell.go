@@ -0,0 +1,371 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Macrocell files are HashLife's quadtree, each line a node: the leaves,
+// 8x8 cells, as rows of . and * ended by $, and the other nodes as
+// "level nw ne sw se", the children being the numbers of the lines
+// where they are, not counting the header and comments, and 0 when empty.
+// The last node is the root
+const macrocellLeafLevel = 3
+
+type macrocell struct {
+	PatternMetadata
+	Generation uint64
+	root       *quadNode
+}
+
+func parseMacrocellLeaf(tree *quadtree, line string, lineNumber int) (*quadNode, error) {
+	node := tree.emptyNode(macrocellLeafLevel)
+
+	x, y := 0, 0
+
+	for column, c := range line {
+		if c == '$' {
+			x, y = 0, y+1
+			continue
+		}
+
+		if x >= 1<<macrocellLeafLevel || y >= 1<<macrocellLeafLevel {
+			return nil, importError(lineNumber, column+1, "Leaf cells must fit in 8x8")
+		}
+
+		if c == '*' {
+			node = tree.setCell(node, x, y, true)
+		}
+
+		x++
+	}
+
+	return node, nil
+}
+
+func parseMacrocell(tree *quadtree, content string) (macrocell, error) {
+	lines := strings.Split(content, "\n")
+
+	if !strings.HasPrefix(strings.TrimSpace(lines[0]), "[M2]") {
+		return macrocell{}, errors.New("Missing \"[M2]\" header")
+	}
+
+	result := macrocell{PatternMetadata: PatternMetadata{Description: []string{}}}
+
+	// Indexes start on 1, 0 being the empty node
+	nodes := []*quadNode{nil}
+
+	for index, line := range lines[1:] {
+		// the header is on the first line
+		lineNumber := index + 2
+
+		trimmed := strings.TrimSpace(line)
+
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		if trimmed[0] == '#' {
+			if len(trimmed) < 2 {
+				continue
+			}
+
+			value := strings.TrimSpace(trimmed[2:])
+
+			switch trimmed[1] {
+			case 'R':
+				result.Rule = value
+			case 'G':
+				generation, err := strconv.ParseUint(value, 10, 64)
+
+				if err != nil {
+					return macrocell{}, importError(lineNumber, 1, "Invalid generation \"%s\"", value)
+				}
+
+				result.Generation = generation
+			case 'N':
+				result.Name = value
+			case 'O':
+				result.setAuthor(value)
+			case 'C', 'D':
+				result.addComment(value)
+			}
+
+			continue
+		}
+
+		if strings.Trim(trimmed, ".*$") == "" {
+			node, err := parseMacrocellLeaf(tree, trimmed, lineNumber)
+
+			if err != nil {
+				return macrocell{}, err
+			}
+
+			nodes = append(nodes, node)
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+
+		if len(fields) != 5 {
+			return macrocell{}, importError(lineNumber, 1, "Expected a \"level nw ne sw se\" node but found \"%s\"", trimmed)
+		}
+
+		level, err := strconv.Atoi(fields[0])
+
+		if err != nil || level <= macrocellLeafLevel || level > 62 {
+			return macrocell{}, importError(lineNumber, 1, "Invalid level \"%s\"", fields[0])
+		}
+
+		var children [4]*quadNode
+
+		for i, field := range fields[1:] {
+			n, err := strconv.Atoi(field)
+
+			switch {
+			case err != nil || n < 0 || n >= len(nodes):
+				return macrocell{}, importError(lineNumber, 1, "Invalid node \"%s\"", field)
+			case n == 0:
+				children[i] = tree.emptyNode(uint(level - 1))
+			case nodes[n].level != uint(level-1):
+				return macrocell{}, importError(lineNumber, 1, "Node %s is not of level %d", field, level-1)
+			default:
+				children[i] = nodes[n]
+			}
+		}
+
+		nodes = append(nodes, tree.join(children[0], children[1], children[2], children[3]))
+	}
+
+	if len(nodes) == 1 {
+		return macrocell{}, errors.New("The pattern has no nodes")
+	}
+
+	result.root = nodes[len(nodes)-1]
+
+	return result, nil
+}
+
+func macrocellLeaf(tree *quadtree, node *quadNode) string {
+	rows := make([][]byte, 1<<macrocellLeafLevel)
+
+	tree.forEachLiveCell(node, NewCoord(0, 0), func(coord Coord) {
+		x, y := coord.Get()
+
+		for len(rows[y]) <= x {
+			rows[y] = append(rows[y], '.')
+		}
+
+		rows[y][x] = '*'
+	})
+
+	// Empty rows at the bottom are implicit
+	for len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+
+	var output string
+
+	for _, row := range rows {
+		output += string(row) + "$"
+	}
+
+	return output
+}
+
+func writeMacrocell(tree *quadtree, pattern macrocell) string {
+	output := "[M2] (toy_gameoflife)\n"
+
+	rule := pattern.Rule
+
+	if rule == "" {
+		rule = DefaultRule
+	}
+
+	output += "#R " + rule + "\n"
+
+	if pattern.Generation != 0 {
+		output += fmt.Sprintf("#G %d\n", pattern.Generation)
+	}
+
+	if pattern.Name != "" {
+		output += "#N " + pattern.Name + "\n"
+	}
+
+	if author := pattern.authorAndYear(); author != "" {
+		output += "#O " + author + "\n"
+	}
+
+	for _, line := range pattern.Description {
+		output += "#C " + line + "\n"
+	}
+
+	root := pattern.root
+
+	for root.level < macrocellLeafLevel {
+		root = tree.expand(root)
+	}
+
+	if root.population == 0 {
+		return output + "$\n"
+	}
+
+	indexes := make(map[*quadNode]int)
+	lines := make([]string, 0)
+
+	var write func(node *quadNode) int
+
+	write = func(node *quadNode) int {
+		if node.population == 0 {
+			return 0
+		}
+
+		if index, found := indexes[node]; found {
+			return index
+		}
+
+		line := ""
+
+		if node.level == macrocellLeafLevel {
+			line = macrocellLeaf(tree, node)
+		} else {
+			line = fmt.Sprintf("%d %d %d %d %d", node.level, write(node.nw), write(node.ne), write(node.sw), write(node.se))
+		}
+
+		lines = append(lines, line)
+		indexes[node] = len(lines)
+
+		return len(lines)
+	}
+
+	write(root)
+
+	return output + strings.Join(lines, "\n") + "\n"
+}
+
+func (this *Importer) ImportFromMacrocellString(content string) (Pattern, error) {
+	tree := newQuadtree()
+
+	parsed, err := parseMacrocell(&tree, content)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	cells := make([]Coord, 0, parsed.root.population)
+
+	tree.forEachLiveCell(parsed.root, NewCoord(0, 0), func(coord Coord) {
+		cells = append(cells, coord)
+	})
+
+	specie, err := specieFromCoords(cells)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	return Pattern{specie, parsed.PatternMetadata}, nil
+}
+
+func PatternToMacrocell(pattern Pattern) string {
+	cells := make([]Coord, 0)
+
+	for y, row := range pattern.Specie {
+		for x, cell := range row {
+			if cell != 0 {
+				cells = append(cells, NewCoord(x, y))
+			}
+		}
+	}
+
+	tree := newQuadtree()
+	root, _ := tree.fromCoords(cells)
+
+	return writeMacrocell(&tree, macrocell{pattern.PatternMetadata, 0, root})
+}
+
+// Replaces the universe with the one in the file, whose root is centred on 0, 0,
+// as in Golly, returning what the file tells about it
+func (this *HashLifeGenerator) LoadMacrocell(content string) (PatternMetadata, error) {
+	tree := newQuadtree()
+
+	parsed, err := parseMacrocell(&tree, content)
+
+	if err != nil {
+		return PatternMetadata{}, err
+	}
+
+	half := -(1 << (parsed.root.level - 1))
+
+	this.tree, this.root, this.origin = tree, parsed.root, NewCoord(half, half)
+	this.Generation = parsed.Generation
+
+	return parsed.PatternMetadata, nil
+}
+
+// Adds the pattern in the file to the universe, the top left corner of its
+// cells at coord, without ever building a specie out of it, as huge patterns
+// would not fit in one. Returns what the file tells about the pattern
+func (this *HashLifeGenerator) PlaceMacrocell(content string, coord Coord) (PatternMetadata, error) {
+	parsed, err := parseMacrocell(&this.tree, content)
+
+	if err != nil {
+		return PatternMetadata{}, err
+	}
+
+	topLeft, _, found := this.tree.bounds(parsed.root)
+
+	if !found {
+		return parsed.PatternMetadata, nil
+	}
+
+	x, y := coord[0]-topLeft[0], coord[1]-topLeft[1]
+	size := 1 << parsed.root.level
+
+	fits := func() bool {
+		rootSize := 1 << this.root.level
+		ox, oy := this.origin.Get()
+
+		return this.root.level >= parsed.root.level && x >= ox && y >= oy && x+size <= ox+rootSize && y+size <= oy+rootSize
+	}
+
+	for !fits() {
+		this.expand()
+	}
+
+	placed := this.tree.placeAt(parsed.root, this.root.level, x-this.origin[0], y-this.origin[1])
+	this.root = this.tree.union(this.root, placed)
+
+	return parsed.PatternMetadata, nil
+}
+
+// The root moved so that its centre is on 0, 0, as Golly writes it
+func (this *HashLifeGenerator) centredRoot() *quadNode {
+	ox, oy := this.origin.Get()
+	size := 1 << this.root.level
+
+	level := this.root.level + 1
+
+	for {
+		half := 1 << (level - 1)
+
+		if -half <= ox && -half <= oy && ox+size <= half && oy+size <= half {
+			return this.tree.placeAt(this.root, level, ox+half, oy+half)
+		}
+
+		level++
+	}
+}
+
+// The universe as it is, without ever building a specie out of it,
+// as huge patterns would not fit in one. The root is centred on 0, 0,
+// so loading it with LoadMacrocell puts the cells back where they were
+func (this *HashLifeGenerator) Macrocell(metadata PatternMetadata) string {
+	if metadata.Rule == "" {
+		metadata.Rule = this.Rule.String()
+	}
+
+	return writeMacrocell(&this.tree, macrocell{metadata, this.Generation, this.centredRoot()})
+}
@@ -0,0 +1,294 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The shape of the neighbourhood, named as in the Larger than Life rule strings
+type NeighbourhoodType string
+
+const (
+	MooreNeighbourhood        NeighbourhoodType = "M"
+	VonNeumannNeighbourhood   NeighbourhoodType = "N"
+	CircularNeighbourhood     NeighbourhoodType = "C"
+	CrossNeighbourhood        NeighbourhoodType = "+"
+	CheckerboardNeighbourhood NeighbourhoodType = "B"
+
+	// In axial coordinates, the 6 neighbours of a hexagonal cell are the Moore
+	// ones but the north-east and south-west corners
+	HexagonalNeighbourhood NeighbourhoodType = "H"
+
+	// In triangular grids, the 3 cells sharing an edge, the 9 sharing only
+	// a vertex and all the 12 of them
+	TriangularEdgesNeighbourhood    NeighbourhoodType = "LE"
+	TriangularVerticesNeighbourhood NeighbourhoodType = "LV"
+	TriangularNeighbourhood         NeighbourhoodType = "L"
+
+	// Given by a mask, as in the configuration file
+	CustomNeighbourhood NeighbourhoodType = "custom"
+)
+
+// For the up triangles, row by row
+var triangularNeighbourOffsets = map[NeighbourhoodType][]Coord{
+	TriangularEdgesNeighbourhood: {
+		NewCoord(-1, 0), NewCoord(1, 0),
+		NewCoord(0, 1),
+	},
+	TriangularVerticesNeighbourhood: {
+		NewCoord(-1, -1), NewCoord(0, -1), NewCoord(1, -1),
+		NewCoord(-2, 0), NewCoord(2, 0),
+		NewCoord(-2, 1), NewCoord(-1, 1), NewCoord(1, 1), NewCoord(2, 1),
+	},
+	TriangularNeighbourhood: {
+		NewCoord(-1, -1), NewCoord(0, -1), NewCoord(1, -1),
+		NewCoord(-2, 0), NewCoord(-1, 0), NewCoord(1, 0), NewCoord(2, 0),
+		NewCoord(-2, 1), NewCoord(-1, 1), NewCoord(0, 1), NewCoord(1, 1), NewCoord(2, 1),
+	},
+}
+
+// The largest range accepted, as in other programs
+const maxNeighbourhoodRange = 500
+
+type Neighbourhood struct {
+	Type  NeighbourhoodType
+	Range int
+
+	// Only for custom neighbourhoods, the weight of each cell around the middle
+	// one, which is the cell itself. Cells with weight 0 are not neighbours
+	Mask [][]int
+}
+
+func NewNeighbourhood(neighbourhoodType NeighbourhoodType, r int) (Neighbourhood, error) {
+	switch neighbourhoodType {
+	case MooreNeighbourhood, VonNeumannNeighbourhood, CircularNeighbourhood, CrossNeighbourhood, CheckerboardNeighbourhood, HexagonalNeighbourhood:
+	case TriangularEdgesNeighbourhood, TriangularVerticesNeighbourhood, TriangularNeighbourhood:
+		if r != 1 {
+			return Neighbourhood{}, errors.New(fmt.Sprintf("Triangular neighbourhoods have range 1, not %d", r))
+		}
+	default:
+		return Neighbourhood{}, errors.New(fmt.Sprintf("Unknown neighbourhood \"%s\"", neighbourhoodType))
+	}
+
+	if r < 1 || r > maxNeighbourhoodRange {
+		return Neighbourhood{}, errors.New(fmt.Sprintf("Invalid neighbourhood range %d", r))
+	}
+
+	return Neighbourhood{Type: neighbourhoodType, Range: r}, nil
+}
+
+// The mask must have odd sides, so that it has a middle, and no negative weights
+func NewNeighbourhoodFromMask(mask [][]int) (Neighbourhood, error) {
+	h := len(mask)
+
+	if h == 0 || h%2 == 0 {
+		return Neighbourhood{}, errors.New("The neighbourhood mask must have an odd number of rows")
+	}
+
+	w := len(mask[0])
+
+	if w%2 == 0 {
+		return Neighbourhood{}, errors.New("The neighbourhood mask must have an odd number of columns")
+	}
+
+	for i, row := range mask {
+		if len(row) != w {
+			return Neighbourhood{}, errors.New(fmt.Sprintf("Row %d of the neighbourhood mask has %d columns instead of %d", i+1, len(row), w))
+		}
+
+		for _, weight := range row {
+			if weight < 0 {
+				return Neighbourhood{}, errors.New("Neighbourhood weights cannot be negative")
+			}
+		}
+	}
+
+	r := h / 2
+
+	if w/2 > r {
+		r = w / 2
+	}
+
+	return Neighbourhood{CustomNeighbourhood, r, mask}, nil
+}
+
+// The 8 surrounding cells
+func DefaultNeighbourhood() Neighbourhood {
+	return Neighbourhood{Type: MooreNeighbourhood, Range: 1}
+}
+
+func (this *Neighbourhood) contains(dx, dy int) bool {
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+
+		return v
+	}
+
+	switch this.Type {
+	case VonNeumannNeighbourhood:
+		return abs(dx)+abs(dy) <= this.Range
+	case CircularNeighbourhood:
+		// Within a distance of r + 1/2 from the cell
+		return dx*dx+dy*dy <= this.Range*this.Range+this.Range
+	case CrossNeighbourhood:
+		return dx == 0 || dy == 0
+	case CheckerboardNeighbourhood:
+		return (abs(dx)+abs(dy))%2 == 1
+	case HexagonalNeighbourhood:
+		return abs(dx-dy) <= this.Range
+	}
+
+	return true
+}
+
+// Relative to the cell, which is not included. The range 1 Moore, von Neumann
+// and hexagonal neighbourhoods go clockwise, the others row by row
+func (this *Neighbourhood) Offsets() []Coord {
+	origin := NewCoord(0, 0)
+
+	if this.Type == CustomNeighbourhood {
+		offsets := make([]Coord, 0)
+
+		for i, row := range this.Mask {
+			for j, weight := range row {
+				dx, dy := j-len(row)/2, i-len(this.Mask)/2
+
+				if weight != 0 && (dx != 0 || dy != 0) {
+					offsets = append(offsets, NewCoord(dx, dy))
+				}
+			}
+		}
+
+		return offsets
+	}
+
+	if this.Range == 1 && this.Type == MooreNeighbourhood {
+		return mooreNeighbours(origin)
+	}
+
+	if this.Range == 1 && (this.Type == VonNeumannNeighbourhood || this.Type == CrossNeighbourhood) {
+		return []Coord{origin.North(), origin.East(), origin.South(), origin.West()}
+	}
+
+	if this.Range == 1 && this.Type == HexagonalNeighbourhood {
+		return hexagonalNeighbours(origin)
+	}
+
+	if this.IsTriangular() {
+		return triangularNeighbourOffsets[this.Type]
+	}
+
+	offsets := make([]Coord, 0)
+
+	for dy := -this.Range; dy <= this.Range; dy++ {
+		for dx := -this.Range; dx <= this.Range; dx++ {
+			if (dx != 0 || dy != 0) && this.contains(dx, dy) {
+				offsets = append(offsets, NewCoord(dx, dy))
+			}
+		}
+	}
+
+	return offsets
+}
+
+func (this *Neighbourhood) IsTriangular() bool {
+	switch this.Type {
+	case TriangularEdgesNeighbourhood, TriangularVerticesNeighbourhood, TriangularNeighbourhood:
+		return true
+	}
+
+	return false
+}
+
+// The offsets of the neighbours of the cell at coord, which in triangular
+// grids are upside down for the down triangles
+func (this *Neighbourhood) OffsetsAt(coord Coord) []Coord {
+	offsets := this.Offsets()
+
+	if !this.IsTriangular() || IsUpTriangle(coord) {
+		return offsets
+	}
+
+	flipped := make([]Coord, len(offsets))
+
+	for i, offset := range offsets {
+		x, y := offset.Get()
+		flipped[i] = NewCoord(x, -y)
+	}
+
+	return flipped
+}
+
+// How much a live cell at offset adds to the count of live neighbours
+func (this *Neighbourhood) Weight(offset Coord) int {
+	if this.Type != CustomNeighbourhood {
+		return 1
+	}
+
+	x, y := offset.Get()
+	h, w := len(this.Mask), len(this.Mask[0])
+
+	if x < -w/2 || x > w/2 || y < -h/2 || y > h/2 {
+		return 0
+	}
+
+	return this.Mask[y+h/2][x+w/2]
+}
+
+// How much the cell itself adds to its count of live neighbours
+func (this *Neighbourhood) MiddleWeight() int {
+	if this.Type != CustomNeighbourhood {
+		return 0
+	}
+
+	return this.Mask[len(this.Mask)/2][len(this.Mask[0])/2]
+}
+
+// The largest count of live neighbours, the cell itself included
+func (this *Neighbourhood) TotalWeight() int {
+	total := this.MiddleWeight()
+
+	for _, offset := range this.Offsets() {
+		total += this.Weight(offset)
+	}
+
+	return total
+}
+
+// How many neighbours each cell has
+func (this *Neighbourhood) Size() int {
+	return len(this.Offsets())
+}
+
+// A ruleset evaluated over another neighbourhood than its own,
+// counting the weights of the live neighbours
+type CustomNeighbourhoodRule struct {
+	Ruleset
+	Neighbourhood Neighbourhood
+}
+
+func NewCustomNeighbourhoodRule(ruleset Ruleset, neighbourhood Neighbourhood) (Ruleset, error) {
+	inner := ruleset
+
+	if generations, ok := ruleset.(*GenerationsRule); ok {
+		inner = generations.Ruleset
+	}
+
+	// Those depend on the position of each of the 8 neighbours
+	if _, ok := inner.(*IsotropicRule); ok {
+		return nil, errors.New(fmt.Sprintf("Rule %s does not support custom neighbourhoods", ruleset))
+	}
+
+	// Larger counts could not be told in the rule string
+	if lifeLike, ok := inner.(*LifeLikeRule); ok && neighbourhood.TotalWeight() >= len(lifeLike.Birth) {
+		return nil, errors.New(fmt.Sprintf("Rule %s counts up to %d live neighbours, but the weights of the mask add up to %d", ruleset, len(lifeLike.Birth)-1, neighbourhood.TotalWeight()))
+	}
+
+	return &CustomNeighbourhoodRule{ruleset, neighbourhood}, nil
+}
+
+func (this *CustomNeighbourhoodRule) GetNeighbourhood() Neighbourhood {
+	return this.Neighbourhood
+}
@@ -0,0 +1,81 @@
+package gameoflife
+
+import (
+	"sync"
+)
+
+// The cells to evaluate, split in stripes of rows, one for each worker.
+// The stable ones are skipped right away
+func (this *Generator) stripes() [][]Coord {
+	coords := make([]Coord, 0, len(this.World.ActiveMatrix))
+
+	this.World.ForEachCoordinate(func(coord Coord) {
+		if this.isStable(coord) {
+			this.skip(coord)
+			return
+		}
+
+		coords = append(coords, coord)
+	})
+
+	stripes := make([][]Coord, this.Workers)
+
+	if len(coords) == 0 {
+		return stripes
+	}
+
+	minY, maxY := coords[0][1], coords[0][1]
+
+	for _, coord := range coords {
+		if coord[1] < minY {
+			minY = coord[1]
+		}
+
+		if coord[1] > maxY {
+			maxY = coord[1]
+		}
+	}
+
+	rows := (maxY - minY + this.Workers) / this.Workers
+
+	for _, coord := range coords {
+		i := (coord[1] - minY) / rows
+		stripes[i] = append(stripes[i], coord)
+	}
+
+	return stripes
+}
+
+// Same as Step, with each worker reading the current generation and
+// keeping what it finds to itself. The next generation is then put
+// together in the order of the stripes
+func (this *Generator) stepInParallel() {
+	stripes := this.stripes()
+	steps := make([][]cellStep, len(stripes))
+
+	var wg sync.WaitGroup
+
+	for i, stripe := range stripes {
+		wg.Add(1)
+
+		go func(steps *[]cellStep, stripe []Coord) {
+			defer wg.Done()
+
+			for _, coord := range stripe {
+				*steps = append(*steps, this.evaluate(coord))
+			}
+		}(&steps[i], stripe)
+	}
+
+	wg.Wait()
+
+	tiles := make(map[Coord]bool)
+
+	for _, stripe := range steps {
+		for _, step := range stripe {
+			this.apply(step, tiles)
+		}
+	}
+
+	this.finishStep(tiles)
+}
@@ -0,0 +1,118 @@
+package gameoflife
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+type PatternMetadata struct {
+	Name   string
+	Author string
+
+	// Zero when unknown
+	Year int
+
+	// Free text lines, such as #D in Life 1.05 or #C in RLE
+	Description []string
+
+	// Rule the pattern was designed for, empty if unknown
+	Rule string
+}
+
+// A specie together with what is known about it
+type Pattern struct {
+	Specie Specie
+	PatternMetadata
+}
+
+func NewPattern(specie Specie) Pattern {
+	return Pattern{specie, PatternMetadata{Description: []string{}}}
+}
+
+func parseYear(value string) (int, bool) {
+	year, err := strconv.Atoi(value)
+	return year, err == nil && year > 0 && len(value) == 4
+}
+
+// Authors often come as "Dean Hickerson, 1989"
+func (this *PatternMetadata) setAuthor(author string) {
+	if i := strings.LastIndex(author, ","); i >= 0 {
+		if year, ok := parseYear(strings.TrimSpace(author[i+1:])); ok {
+			this.Year = year
+			author = author[:i]
+		}
+	}
+
+	this.Author = strings.TrimSpace(author)
+}
+
+// Comments in the form "Key: value" fill the known fields, any other goes to the description
+func (this *PatternMetadata) addComment(comment string) {
+	comment = strings.TrimSpace(comment)
+
+	if s := strings.SplitN(comment, ":", 2); len(s) == 2 {
+		value := strings.TrimSpace(s[1])
+
+		switch strings.ToLower(s[0]) {
+		case "name":
+			this.Name = value
+			return
+		case "author":
+			this.setAuthor(value)
+			return
+		case "year", "discovered":
+			if year, ok := parseYear(value); ok {
+				this.Year = year
+				return
+			}
+		case "rule":
+			this.Rule = value
+			return
+		}
+	}
+
+	this.Description = append(this.Description, comment)
+}
+
+// Patterns that do not tell their rule are assumed to run under any rule
+func (this *PatternMetadata) RunsUnderRule(rule Ruleset) bool {
+	if this.Rule == "" {
+		return true
+	}
+
+	designedFor, err := ParseRule(this.Rule)
+
+	return err == nil && designedFor.String() == rule.String()
+}
+
+// A pattern in the configuration file is either just the specie matrix or an
+// object as {"Specie": [[1,1,1]], "Name": "Blinker", "Rule": "B3/S23"}
+func (this *Pattern) UnmarshalJSON(content []byte) error {
+	var specie Specie
+
+	if err := json.Unmarshal(content, &specie); err == nil {
+		if _, err := NewSpecie(specie); err != nil {
+			return err
+		}
+
+		*this = NewPattern(specie)
+		return nil
+	}
+
+	type plainPattern Pattern
+
+	var pattern plainPattern
+
+	if err := json.Unmarshal(content, &pattern); err != nil {
+		return err
+	}
+
+	if _, err := NewSpecie(pattern.Specie); err != nil {
+		return err
+	}
+
+	*this = Pattern(pattern)
+
+	return nil
+}
@@ -4,16 +4,77 @@ import (
 	"strings"
 )
 
+// Glyphs for the dying states, from the youngest to the oldest ones
+const dyingCellGlyphs = "*+=-:"
+
+// The rectangle of the world that is printed
+type Viewport struct {
+	Origin        Coord
+	Height, Width int
+}
+
 type Printer struct {
-	World *World
+	Universe Universe
+	Viewport Viewport
+
+	// Whether the viewport moves to keep the live cells in it
+	Follow bool
+}
+
+// Prints the whole universe
+func NewPrinter(universe Universe) Printer {
+	h, w := universe.Size()
+	return Printer{universe, Viewport{NewCoord(0, 0), h, w}, false}
+}
+
+func NewViewportPrinter(universe Universe, viewport Viewport, follow bool) Printer {
+	return Printer{universe, viewport, follow}
+}
+
+// Cells out of the universe are dead
+func (this *Printer) cell(coord Coord) Cell {
+	cell, _ := this.Universe.GetCell(coord)
+	return cell
+}
+
+// Centres the viewport on the cells that are not dead, unless they are all in it already
+func (this *Printer) FollowCells() {
+	topLeft, bottomRight, found := this.Universe.Bounds()
+
+	if !found {
+		return
+	}
+
+	left, top := topLeft.Get()
+	right, bottom := bottomRight.Get()
+	x, y := this.Viewport.Origin.Get()
+	h, w := this.Viewport.Height, this.Viewport.Width
+
+	if left >= x && right < x+w && top >= y && bottom < y+h {
+		return
+	}
+
+	this.Viewport.Origin = NewCoord((left+right+1-w)/2, (top+bottom+1-h)/2)
 }
 
-func NewPrinter(world *World) Printer {
-	return Printer{world}
+func CellGlyph(cell Cell) string {
+	if cell == DeadCell {
+		return " "
+	}
+
+	if cell == LiveCell {
+		return "o"
+	}
+
+	if dying := int(cell - LiveCell - 1); dying < len(dyingCellGlyphs) {
+		return string(dyingCellGlyphs[dying])
+	}
+
+	return "."
 }
 
 func (this *Printer) PrintHorizontalBorder() string {
-	_, w := this.World.Size()
+	w := this.Viewport.Width
 	return strings.Repeat("#", w+2) + "\n"
 }
 
@@ -22,13 +83,10 @@ func (this *Printer) PrintLine(line, w int) string {
 
 	output += "#"
 
-	for i := 0; i < w; i++ {
-		if live, _ := this.World.IsCellLive(NewCoord(i, line)); live {
-			output += "o"
-			continue
-		}
+	x := this.Viewport.Origin[0]
 
-		output += " "
+	for i := 0; i < w; i++ {
+		output += CellGlyph(this.cell(NewCoord(x+i, line)))
 	}
 
 	output += "#\n"
@@ -36,15 +94,88 @@ func (this *Printer) PrintLine(line, w int) string {
 	return output
 }
 
+// Each row is half a cell to the left of the one above, so every cell
+// touches its 6 neighbours, making the world a rhombus
+func (this *Printer) PrintHexagonal() string {
+	h, w := this.Viewport.Height, this.Viewport.Width
+	originX, originY := this.Viewport.Origin.Get()
+
+	border := func(indentation int) string {
+		return strings.Repeat(" ", indentation) + strings.TrimSpace(strings.Repeat("# ", w+2)) + "\n"
+	}
+
+	output := border(h + 1)
+
+	for y := 0; y < h; y++ {
+		glyphs := make([]string, w)
+
+		for x := range glyphs {
+			glyphs[x] = CellGlyph(this.cell(NewCoord(originX+x, originY+y)))
+		}
+
+		output += strings.Repeat(" ", h-y) + "# " + strings.Join(glyphs, " ") + " #\n"
+	}
+
+	return output + border(0)
+}
+
+// Dead triangles are drawn as their sides, / for the up ones and \ for the
+// down ones, so the rows alternate between them. Live ones are A and V
+func (this *Printer) PrintTriangular() string {
+	h, w := this.Viewport.Height, this.Viewport.Width
+	originX, originY := this.Viewport.Origin.Get()
+
+	output := this.PrintHorizontalBorder()
+
+	for y := 0; y < h; y++ {
+		output += "#"
+
+		for x := 0; x < w; x++ {
+			coord := NewCoord(originX+x, originY+y)
+			cell := this.cell(coord)
+			up := IsUpTriangle(coord)
+
+			switch {
+			case cell == DeadCell && up:
+				output += "/"
+			case cell == DeadCell:
+				output += "\\"
+			case cell == LiveCell && up:
+				output += "A"
+			case cell == LiveCell:
+				output += "V"
+			default:
+				output += CellGlyph(cell)
+			}
+		}
+
+		output += "#\n"
+	}
+
+	return output + this.PrintHorizontalBorder()
+}
+
 func (this *Printer) Print() string {
+	if this.Follow {
+		this.FollowCells()
+	}
+
+	switch this.Universe.GetGrid() {
+	case HexagonalGrid:
+		return this.PrintHexagonal()
+	case TriangularGrid:
+		return this.PrintTriangular()
+	}
+
 	var output string
 
 	output += this.PrintHorizontalBorder()
 
-	h, w := this.World.Size()
+	h, w := this.Viewport.Height, this.Viewport.Width
+	y := this.Viewport.Origin[1]
 
 	for i := 0; i < h; i++ {
-		output += this.PrintLine(i, w)
+		output += this.PrintLine(y+i, w)
 	}
 
 	output += this.PrintHorizontalBorder()
@@ -0,0 +1,203 @@
+package gameoflife
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type rleHeader struct {
+	Width, Height int
+	Rule          string
+}
+
+// Parses a line such as "x = 3, y = 3, rule = B3/S23"
+func parseRLEHeader(line string, lineNumber int) (rleHeader, error) {
+	header := rleHeader{-1, -1, ""}
+
+	column := 1
+
+	for _, field := range strings.Split(line, ",") {
+		keyValue := strings.SplitN(field, "=", 2)
+
+		if len(keyValue) != 2 {
+			return rleHeader{}, importError(lineNumber, column, "Invalid header field \"%s\"", strings.TrimSpace(field))
+		}
+
+		key := strings.TrimSpace(keyValue[0])
+		value := strings.TrimSpace(keyValue[1])
+
+		switch key {
+		case "x", "y":
+			n, err := strconv.Atoi(value)
+
+			if err != nil || n < 0 {
+				return rleHeader{}, importError(lineNumber, column, "Invalid value \"%s\" for \"%s\"", value, key)
+			}
+
+			if key == "x" {
+				header.Width = n
+			} else {
+				header.Height = n
+			}
+		case "rule":
+			header.Rule = value
+		default:
+			return rleHeader{}, importError(lineNumber, column, "Unknown header field \"%s\"", key)
+		}
+
+		// the extra one is the comma
+		column += len(field) + 1
+	}
+
+	if header.Width < 0 || header.Height < 0 {
+		return rleHeader{}, importError(lineNumber, 1, "Header must define both \"x\" and \"y\"")
+	}
+
+	return header, nil
+}
+
+func (this *Importer) ImportFromRLEString(content string) (Pattern, error) {
+	lines := strings.Split(content, "\n")
+
+	pattern := NewPattern(Specie{})
+
+	lineIndex := 0
+
+	// Comments (#N, #C, #O...) and blank lines may only come before the header
+	for ; lineIndex < len(lines); lineIndex++ {
+		trimmed := strings.TrimSpace(lines[lineIndex])
+
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		if trimmed[0] != '#' {
+			break
+		}
+
+		if len(trimmed) < 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(trimmed[2:])
+
+		switch trimmed[1] {
+		case 'N':
+			pattern.Name = value
+		case 'O':
+			pattern.setAuthor(value)
+		case 'C', 'c':
+			pattern.addComment(value)
+		}
+	}
+
+	if lineIndex == len(lines) {
+		return Pattern{}, errors.New("Missing RLE header")
+	}
+
+	header, err := parseRLEHeader(strings.TrimRight(lines[lineIndex], " \t\r"), lineIndex+1)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	pattern.Rule = header.Rule
+
+	rows := make([][]int, header.Height)
+
+	for i := range rows {
+		rows[i] = make([]int, header.Width)
+	}
+
+	row, column := 0, 0
+
+	// a run count waiting for its tag and where it has started
+	count, countLine, countColumn := 0, 0, 0
+
+	finished := false
+
+	for lineIndex++; lineIndex < len(lines) && !finished; lineIndex++ {
+		line := strings.TrimRight(lines[lineIndex], " \t\r")
+		lineNumber := lineIndex + 1
+
+		if len(line) > 0 && line[0] == '#' {
+			continue
+		}
+
+		for index, c := range line {
+			charColumn := index + 1
+
+			if c >= '0' && c <= '9' {
+				if count == 0 {
+					if c == '0' {
+						return Pattern{}, importError(lineNumber, charColumn, "Run count cannot start with \"0\"")
+					}
+
+					countLine, countColumn = lineNumber, charColumn
+				}
+
+				count = count*10 + int(c-'0')
+				continue
+			}
+
+			if c == ' ' || c == '\t' {
+				continue
+			}
+
+			run := count
+
+			if run == 0 {
+				run = 1
+			}
+
+			count = 0
+
+			switch c {
+			case 'b', 'o':
+				if column+run > header.Width {
+					return Pattern{}, importError(lineNumber, charColumn, "Row %d is wider than the declared width %d", row+1, header.Width)
+				}
+
+				if row >= header.Height {
+					return Pattern{}, importError(lineNumber, charColumn, "Pattern is taller than the declared height %d", header.Height)
+				}
+
+				if c == 'o' {
+					for i := column; i < column+run; i++ {
+						rows[row][i] = 1
+					}
+				}
+
+				column += run
+			case '$':
+				row += run
+				column = 0
+			case '!':
+				finished = true
+			default:
+				return Pattern{}, importError(lineNumber, charColumn, "Invalid char \"%c\"", c)
+			}
+
+			if finished {
+				break
+			}
+		}
+	}
+
+	if count != 0 {
+		return Pattern{}, importError(countLine, countColumn, "Run count %d is not followed by a tag", count)
+	}
+
+	if !finished {
+		return Pattern{}, importError(len(lines), len(lines[len(lines)-1])+1, "Missing \"!\" at the end of the pattern")
+	}
+
+	pattern.Specie, err = NewSpecie(rows)
+
+	if err != nil {
+		return Pattern{}, err
+	}
+
+	return pattern, nil
+}
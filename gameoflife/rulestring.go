@@ -0,0 +1,324 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A family of rules described by a rule string, such as B3/S23
+type Ruleset interface {
+	CreateRules(world *World) []Rule
+
+	// How many states a cell can be in, 2 unless there are dying cells
+	States() int
+
+	// The cells counted as neighbours, which the world is set to
+	GetNeighbourhood() Neighbourhood
+
+	String() string
+}
+
+// Totalistic rules, where only the number of live neighbours matters
+type LifeLikeRule struct {
+	// By number of live neighbours, up to as many as there are
+	Birth, Survival []bool
+
+	// Only the orthogonal neighbours count, as in B2/S1V
+	VonNeumann bool
+
+	// For hexagonal grids, where there are 6 neighbours, as in B2/S34H
+	Hexagonal bool
+
+	// For triangular grids, which of the triangular neighbourhoods, as in B4/S345L.
+	// Empty otherwise
+	Triangular NeighbourhoodType
+}
+
+// Counts are digits, or numbers separated by commas, as 3,10,12 or 12, alone. Up to 9
+// neighbours, the digits can be in any order. Above that, they must be in
+// increasing order, so that numbers of two digits are told apart: a digit
+// not bigger than the previous one starts one, as in 56789101112
+func digitsToCounts(digits string, max int) ([]bool, bool) {
+	counts := make([]bool, max+1)
+
+	if strings.Contains(digits, ",") {
+		for _, field := range strings.Split(digits, ",") {
+			if field == "" {
+				continue
+			}
+
+			n, err := strconv.Atoi(field)
+
+			if err != nil || n < 0 || n > max {
+				return counts, false
+			}
+
+			counts[n] = true
+		}
+
+		return counts, true
+	}
+
+	previous := -1
+
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return counts, false
+		}
+
+		n := int(digits[i] - '0')
+
+		if max >= 10 && n <= previous && i+1 < len(digits) && digits[i+1] >= '0' && digits[i+1] <= '9' {
+			i++
+			n = n*10 + int(digits[i]-'0')
+		}
+
+		if n > max || max >= 10 && n <= previous {
+			return counts, false
+		}
+
+		counts[n] = true
+		previous = n
+	}
+
+	return counts, true
+}
+
+// Separated by commas only when the digits alone would be read otherwise
+func countsToDigits(counts []bool) string {
+	fields := make([]string, 0)
+
+	for n, set := range counts {
+		if set {
+			fields = append(fields, strconv.Itoa(n))
+		}
+	}
+
+	digits := strings.Join(fields, "")
+
+	if parsed, ok := digitsToCounts(digits, len(counts)-1); !ok || fmt.Sprint(parsed) != fmt.Sprint(counts) {
+		// A count alone needs a comma too, as 12,
+		if len(fields) == 1 {
+			return fields[0] + ","
+		}
+
+		return strings.Join(fields, ",")
+	}
+
+	return digits
+}
+
+// Splits "B3/S23", "S23/B3" or "B3S23" into its birth and survival parts
+func splitBirthSurvival(rule string) (birth, survival string, ok bool) {
+	s := strings.Replace(rule, "/", "", 1)
+
+	b, si := strings.Index(s, "B"), strings.Index(s, "S")
+
+	if b < 0 || si < 0 || strings.Count(s, "B") != 1 || strings.Count(s, "S") != 1 || (b != 0 && si != 0) {
+		return "", "", false
+	}
+
+	if b < si {
+		return s[b+1 : si], s[si+1:], true
+	}
+
+	return s[b+1:], s[si+1 : b], true
+}
+
+// Understands B36/S23, S23/B36, B36S23, the older S/B 23/36 and, for any of
+// them, the V suffix for the von Neumann neighbourhood, H for the hexagonal
+// one or L, LE and LV for the triangular ones, with up to 12 neighbours
+func ParseLifeLikeRule(rule string) (LifeLikeRule, error) {
+	invalid := errors.New(fmt.Sprintf("Invalid rule \"%s\"", rule))
+
+	s := strings.ToUpper(strings.Replace(rule, " ", "", -1))
+
+	result := LifeLikeRule{}
+
+	maxNeighbours := 8
+
+	switch {
+	case strings.HasSuffix(s, "LE"), strings.HasSuffix(s, "LV"):
+		result.Triangular = NeighbourhoodType(s[len(s)-2:])
+		maxNeighbours = len(triangularNeighbourOffsets[result.Triangular])
+		s = s[:len(s)-2]
+	case strings.HasSuffix(s, "L"):
+		result.Triangular = TriangularNeighbourhood
+		maxNeighbours = len(triangularNeighbourOffsets[result.Triangular])
+		s = s[:len(s)-1]
+	case strings.HasSuffix(s, "V"):
+		result.VonNeumann = true
+		maxNeighbours = 4
+		s = s[:len(s)-1]
+	case strings.HasSuffix(s, "H"):
+		result.Hexagonal = true
+		maxNeighbours = 6
+		s = s[:len(s)-1]
+	}
+
+	birth, survival, ok := splitBirthSurvival(s)
+
+	if !ok {
+		parts := strings.Split(s, "/")
+
+		if len(parts) != 2 || strings.ContainsAny(s, "BS") {
+			return LifeLikeRule{}, invalid
+		}
+
+		survival, birth = parts[0], parts[1]
+	}
+
+	var birthOk, survivalOk bool
+
+	result.Birth, birthOk = digitsToCounts(birth, maxNeighbours)
+	result.Survival, survivalOk = digitsToCounts(survival, maxNeighbours)
+
+	if !birthOk || !survivalOk {
+		return LifeLikeRule{}, invalid
+	}
+
+	// Only cells around live ones are ever evaluated
+	if result.Birth[0] {
+		return LifeLikeRule{}, errors.New(fmt.Sprintf("Rules with birth on 0 neighbours, as \"%s\", are not supported", rule))
+	}
+
+	return result, nil
+}
+
+func (this *LifeLikeRule) String() string {
+	s := "B" + countsToDigits(this.Birth) + "/S" + countsToDigits(this.Survival)
+
+	if this.VonNeumann {
+		return s + "V"
+	}
+
+	if this.Hexagonal {
+		return s + "H"
+	}
+
+	if this.Triangular != "" {
+		return s + string(this.Triangular)
+	}
+
+	return s
+}
+
+func (this *LifeLikeRule) States() int {
+	return 2
+}
+
+func (this *LifeLikeRule) GetNeighbourhood() Neighbourhood {
+	if this.VonNeumann {
+		return Neighbourhood{Type: VonNeumannNeighbourhood, Range: 1}
+	}
+
+	if this.Hexagonal {
+		return Neighbourhood{Type: HexagonalNeighbourhood, Range: 1}
+	}
+
+	if this.Triangular != "" {
+		return Neighbourhood{Type: this.Triangular, Range: 1}
+	}
+
+	return DefaultNeighbourhood()
+}
+
+func (this *LifeLikeRule) CreateRules(world *World) []Rule {
+	matrix := world.GetActiveMatrix()
+
+	countLiveNeighbours := func(neighbours NeighboursCoords, coord Coord) int {
+		if world.Neighbourhood.Type == CustomNeighbourhood {
+			return world.CountLiveNeighbours(coord)
+		}
+
+		if this.VonNeumann {
+			neighbours = world.GetCellVonNeumannNeighboursCoords(coord)
+		}
+
+		if this.Hexagonal {
+			neighbours = world.GetCellHexagonalNeighboursCoords(coord)
+		}
+
+		if this.Triangular != "" {
+			neighbours = world.GetCellNeighboursCoordsIn(coord, this.GetNeighbourhood())
+		}
+
+		// NOTE: this is similar to reduce(sum)
+		count := 0
+
+		for _, coord := range neighbours {
+			if matrix.IsLive(coord) {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	return []Rule{
+		NewRule(func(coord Coord) bool {
+			// Applies to dead cells
+			return !matrix.IsLive(coord)
+		}, func(neighbours NeighboursCoords, coord Coord) bool {
+			count := countLiveNeighbours(neighbours, coord)
+			return count < len(this.Birth) && this.Birth[count]
+		}),
+
+		NewRule(func(coord Coord) bool {
+			// Applies to live cells
+			return matrix.IsLive(coord)
+		}, func(neighbours NeighboursCoords, coord Coord) bool {
+			count := countLiveNeighbours(neighbours, coord)
+			return count < len(this.Survival) && this.Survival[count]
+		}),
+	}
+}
+
+func ParseRule(rule string) (Ruleset, error) {
+	// Larger than Life rules start with the range, as R5
+	if s := strings.ToUpper(strings.TrimSpace(rule)); len(s) > 1 && s[0] == 'R' && s[1] >= '0' && s[1] <= '9' {
+		largerThanLife, err := ParseLargerThanLifeRule(rule)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &largerThanLife, nil
+	}
+
+	if strings.Count(rule, "/") == 2 {
+		return ParseGenerationsRule(rule)
+	}
+
+	// Hensel letters, or their negation, mean a non-totalistic rule,
+	// but for the E in the suffix of the triangular edges neighbourhood
+	if strings.ContainsAny(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(rule)), "le"), "cekainyqjrtwz-") {
+		isotropic, err := ParseIsotropicRule(rule)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &isotropic, nil
+	}
+
+	lifeLike, err := ParseLifeLikeRule(rule)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &lifeLike, nil
+}
+
+func CreateRulesFromString(world *World, rule string) ([]Rule, error) {
+	ruleset, err := ParseRule(rule)
+
+	if err != nil {
+		return []Rule{}, err
+	}
+
+	return ruleset.CreateRules(world), nil
+}
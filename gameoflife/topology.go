@@ -0,0 +1,264 @@
+package gameoflife
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// How the edges of a bounded world are joined, as in Golly
+type TopologyType string
+
+const (
+	PlaneTopology        TopologyType = "P"
+	TorusTopology        TopologyType = "T"
+	KleinBottleTopology  TopologyType = "K"
+	CrossSurfaceTopology TopologyType = "C"
+	SphereTopology       TopologyType = "S"
+
+	// Not bounded at all, so it has no Golly notation
+	UnboundedTopology TopologyType = "unbounded"
+)
+
+var topologyNames = map[string]TopologyType{
+	"plane":         PlaneTopology,
+	"torus":         TorusTopology,
+	"klein":         KleinBottleTopology,
+	"cross-surface": CrossSurfaceTopology,
+	"sphere":        SphereTopology,
+	"unbounded":     UnboundedTopology,
+}
+
+type Topology struct {
+	Type TopologyType
+
+	// 0 when the size comes from elsewhere, as the configuration file
+	Width, Height int
+
+	// For Klein bottles, whether the top and bottom edges are joined with a twist,
+	// otherwise it is the left and right ones
+	TwistedHorizontalEdges bool
+
+	// For tori, how many cells to the right crossing the top or bottom edges
+	// moves a cell, and how many cells down crossing the left or right ones does.
+	// Only one of them can be set
+	HorizontalShift, VerticalShift int
+}
+
+// Understands the names of the topologies, which take the size from elsewhere,
+// or Golly's notation, which includes it: P30,20 for a plane, T30,20 for a torus,
+// T30+5,20 and T30,20+5 for shifted ones, K30*,20 and K30,20* for Klein bottles,
+// with the asterisk on the twisted edges, C30,20 for a cross-surface and S30 for a sphere
+func ParseTopology(spec string) (Topology, error) {
+	invalid := errors.New(fmt.Sprintf("Invalid topology \"%s\"", spec))
+
+	s := strings.Replace(spec, " ", "", -1)
+
+	if s == "" {
+		return Topology{Type: PlaneTopology}, nil
+	}
+
+	if t, found := topologyNames[strings.ToLower(s)]; found {
+		return Topology{Type: t, TwistedHorizontalEdges: t == KleinBottleTopology}, nil
+	}
+
+	topology := Topology{Type: TopologyType(strings.ToUpper(s[:1]))}
+
+	// Each size can be followed by a shift or an asterisk
+	parseSize := func(size string) (int, int, bool, bool) {
+		twisted := strings.HasSuffix(size, "*")
+		size = strings.TrimSuffix(size, "*")
+
+		shift := 0
+		split := strings.IndexAny(size, "+-")
+
+		if split >= 0 {
+			var err error
+
+			if shift, err = strconv.Atoi(size[split:]); err != nil {
+				return 0, 0, false, false
+			}
+
+			size = size[:split]
+		}
+
+		n, err := strconv.Atoi(size)
+
+		return n, shift, twisted, err == nil && n > 0
+	}
+
+	sizes := strings.Split(s[1:], ",")
+
+	if topology.Type == SphereTopology && len(sizes) == 1 {
+		sizes = append(sizes, sizes[0])
+	}
+
+	if len(sizes) != 2 {
+		return Topology{}, invalid
+	}
+
+	w, horizontalShift, twistedHorizontal, okW := parseSize(sizes[0])
+	h, verticalShift, twistedVertical, okH := parseSize(sizes[1])
+
+	if !okW || !okH {
+		return Topology{}, invalid
+	}
+
+	topology.Width, topology.Height = w, h
+
+	shifted := horizontalShift != 0 || verticalShift != 0
+	twisted := twistedHorizontal || twistedVertical
+
+	switch topology.Type {
+	case TorusTopology:
+		if twisted || (horizontalShift != 0 && verticalShift != 0) {
+			return Topology{}, invalid
+		}
+
+		topology.HorizontalShift, topology.VerticalShift = horizontalShift, verticalShift
+	case KleinBottleTopology:
+		if shifted || twistedHorizontal == twistedVertical {
+			return Topology{}, invalid
+		}
+
+		topology.TwistedHorizontalEdges = twistedHorizontal
+	case PlaneTopology, CrossSurfaceTopology:
+		if shifted || twisted {
+			return Topology{}, invalid
+		}
+	case SphereTopology:
+		if shifted || twisted || w != h {
+			return Topology{}, invalid
+		}
+	default:
+		return Topology{}, invalid
+	}
+
+	return topology, nil
+}
+
+// Where val is in a world of the given size and how many times it went around it,
+// the laps being negative when going around to the left or upwards. Neighbours
+// can be farther than one cell away from the border
+func wrap(val, max int) (int, int) {
+	laps := val / max
+
+	if val%max < 0 {
+		laps--
+	}
+
+	return val - laps*max, laps
+}
+
+func NewShiftedTorusWorld(h, w, horizontalShift, verticalShift int) (World, error) {
+	if horizontalShift != 0 && verticalShift != 0 {
+		return World{}, errors.New("Only one of the edges of a torus can be shifted")
+	}
+
+	return NewGenericWorld(h, w, func(coord Coord) Coord {
+		x, y := coord.Get()
+
+		x, horizontalLaps := wrap(x, w)
+		y, verticalLaps := wrap(y, h)
+
+		x, _ = wrap(x+verticalLaps*horizontalShift, w)
+		y, _ = wrap(y+horizontalLaps*verticalShift, h)
+
+		return NewCoord(x, y)
+	})
+}
+
+// Crossing the twisted edges, cells come back on the other side, mirrored
+func NewKleinBottleWorld(h, w int, twistedHorizontalEdges bool) (World, error) {
+	return NewGenericWorld(h, w, func(coord Coord) Coord {
+		x, y := coord.Get()
+
+		x, horizontalLaps := wrap(x, w)
+		y, verticalLaps := wrap(y, h)
+
+		if twistedHorizontalEdges && verticalLaps%2 != 0 {
+			x = w - 1 - x
+		}
+
+		if !twistedHorizontalEdges && horizontalLaps%2 != 0 {
+			y = h - 1 - y
+		}
+
+		return NewCoord(x, y)
+	})
+}
+
+// Both pairs of edges are joined with a twist, as in the projective plane
+func NewCrossSurfaceWorld(h, w int) (World, error) {
+	return NewGenericWorld(h, w, func(coord Coord) Coord {
+		x, y := coord.Get()
+
+		x, horizontalLaps := wrap(x, w)
+		y, verticalLaps := wrap(y, h)
+
+		if verticalLaps%2 != 0 {
+			x = w - 1 - x
+		}
+
+		if horizontalLaps%2 != 0 {
+			y = h - 1 - y
+		}
+
+		return NewCoord(x, y)
+	})
+}
+
+// The top edge is joined to the left one and the bottom edge to the right one,
+// so the world must be square. Crossing two edges at once leads nowhere
+func NewSphereWorld(h, w int) (World, error) {
+	if h != w {
+		return World{}, errors.New("Impossible world")
+	}
+
+	return NewGenericWorld(h, w, func(coord Coord) Coord {
+		x, y := coord.Get()
+
+		insideX, insideY := x >= 0 && x < w, y >= 0 && y < h
+
+		switch {
+		case insideX && insideY:
+			return coord
+		case insideX && y < 0:
+			return NewCoord(-y-1, x)
+		case insideX:
+			return NewCoord(w-1-(y-h), x)
+		case insideY && x < 0:
+			return NewCoord(y, -x-1)
+		case insideY:
+			return NewCoord(y, h-1-(x-w))
+		}
+
+		return NewCoord(-1, -1)
+	})
+}
+
+// The size in the topology, when there is one, replaces the given one.
+// Unbounded worlds have no size
+func NewTopologyWorld(h, w int, topology Topology) (World, error) {
+	if topology.Type == UnboundedTopology {
+		return NewUnboundedWorld(), nil
+	}
+
+	if topology.Width > 0 && topology.Height > 0 {
+		h, w = topology.Height, topology.Width
+	}
+
+	switch topology.Type {
+	case TorusTopology:
+		return NewShiftedTorusWorld(h, w, topology.HorizontalShift, topology.VerticalShift)
+	case KleinBottleTopology:
+		return NewKleinBottleWorld(h, w, topology.TwistedHorizontalEdges)
+	case CrossSurfaceTopology:
+		return NewCrossSurfaceWorld(h, w)
+	case SphereTopology:
+		return NewSphereWorld(h, w)
+	}
+
+	return NewWorld(h, w)
+}
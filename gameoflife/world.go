@@ -4,22 +4,70 @@ import (
 	"errors"
 )
 
-type WorldMatrix map[Coord]bool
+type WorldMatrix map[Coord]Cell
 
 type World struct {
 	// The current Matrix and the next generation one
 	ActiveMatrix, InactiveMatrix WorldMatrix
 	Height, Width                int
 	NeighbourCoordTransformation CoordTransformation
+
+	// Square unless set otherwise
+	Grid Grid
+
+	// Any coordinate is valid, and Height and Width are 0
+	Unbounded bool
+
+	// Only set in worlds made by NewBoundedWorld
+	Boundaries Boundaries
+
+	// Cells outside of the world, with a state that never changes, as the
+	// live and dead borders. They are not visited by ForEachCoordinate
+	fixedCells map[Coord]Cell
+
+	// The cells next to a live border, which are always visited, as cells
+	// are born there even when nothing else is around
+	borderDependents []Coord
+
+	// Which cells around each cell are its neighbours, the Moore one by default
+	Neighbourhood Neighbourhood
+
+	// For the cells whose coordinates add up to an even and to an odd number,
+	// which only differ in triangular grids
+	neighbourOffsets [2][]Coord
+	neighbourWeights []int
+
+	// Cells set since the last step, only recorded when a generator watches them
+	changedCells map[Coord]bool
 }
 
 func (this *WorldMatrix) IsLive(coord Coord) bool {
-	state, found := (*this)[coord]
-	return state && found
+	cell := (*this)[coord]
+	return cell.IsLive()
+}
+
+func (this *WorldMatrix) GetCell(coord Coord) Cell {
+	return (*this)[coord]
+}
+
+func (this *WorldMatrix) SetCell(coord Coord, cell Cell) {
+	(*this)[coord] = cell
 }
 
 func (this *WorldMatrix) SetCellState(coord Coord, state bool) {
-	(*this)[coord] = state
+	if state {
+		this.SetCell(coord, LiveCell)
+		return
+	}
+
+	this.SetCell(coord, DeadCell)
+}
+
+// Makes the cell be visited on the next step, keeping its state
+func (this *WorldMatrix) track(coord Coord) {
+	if _, found := (*this)[coord]; !found {
+		(*this)[coord] = DeadCell
+	}
 }
 
 func CreateMatrix() WorldMatrix {
@@ -28,35 +76,34 @@ func CreateMatrix() WorldMatrix {
 
 func NewGenericWorld(h, w int, transformation CoordTransformation) (World, error) {
 	if h > 0 && w > 0 {
-		return World{CreateMatrix(), CreateMatrix(), h, w, transformation}, nil
+		world := World{ActiveMatrix: CreateMatrix(), InactiveMatrix: CreateMatrix(), Height: h, Width: w, NeighbourCoordTransformation: transformation}
+		world.SetGrid(SquareGrid)
+		return world, nil
 	}
 
 	return World{}, errors.New("Impossible world")
 }
 
+// Patterns can grow forever, as the cells are only stored when they are not dead
+func NewUnboundedWorld() World {
+	world := World{ActiveMatrix: CreateMatrix(), InactiveMatrix: CreateMatrix(), Unbounded: true, NeighbourCoordTransformation: func(coord Coord) Coord {
+		return coord
+	}}
+
+	world.SetGrid(SquareGrid)
+
+	return world
+}
+
 func NewWorld(h, w int) (World, error) {
 	return NewGenericWorld(h, w, func(coord Coord) Coord {
 		return coord
 	})
 }
 
+// A torus
 func NewCircularWorld(h, w int) (World, error) {
-	circulate := func(val, max int) int {
-		if val < 0 {
-			return max - 1
-		}
-
-		if val >= max {
-			return 0
-		}
-
-		return val
-	}
-
-	return NewGenericWorld(h, w, func(coord Coord) Coord {
-		x, y := coord.Get()
-		return NewCoord(circulate(x, w), circulate(y, h))
-	})
+	return NewShiftedTorusWorld(h, w, 0, 0)
 }
 
 func (this *World) GetActiveMatrix() *WorldMatrix {
@@ -70,9 +117,21 @@ func (this *World) GetInactiveMatrix() *WorldMatrix {
 func (this *World) SwapMatrices() {
 	this.ActiveMatrix = this.InactiveMatrix
 	this.InactiveMatrix = CreateMatrix()
+
+	for coord, cell := range this.fixedCells {
+		this.ActiveMatrix.SetCell(coord, cell)
+	}
+
+	for _, coord := range this.borderDependents {
+		this.ActiveMatrix.track(coord)
+	}
 }
 
 func (this *World) IsCoordValid(coord Coord) bool {
+	if this.Unbounded {
+		return true
+	}
+
 	h, w := this.Size()
 	x, y := coord.Get()
 
@@ -87,32 +146,99 @@ func (this *World) IsCellLive(coord Coord) (bool, error) {
 	return false, errors.New("Invalid coord")
 }
 
-func (this *World) ActivateCell(coord Coord) error {
+func (this *World) GetCell(coord Coord) (Cell, error) {
+	if this.IsCoordValid(coord) {
+		return this.ActiveMatrix.GetCell(coord), nil
+	}
+
+	return DeadCell, errors.New("Invalid coord")
+}
+
+func (this *World) SetCell(coord Coord, cell Cell) error {
 	if !this.IsCoordValid(coord) {
 		return errors.New("Invalid coord")
 	}
 
-	for _, n := range this.GetCellNeighboursCoords(coord) {
-		if !this.ActiveMatrix.IsLive(n) {
-			this.ActiveMatrix.SetCellState(n, false)
-		}
+	for _, n := range this.GetCellDependentsCoords(coord) {
+		this.ActiveMatrix.track(n)
 	}
 
-	this.ActiveMatrix.SetCellState(coord, true)
+	this.ActiveMatrix.SetCell(coord, cell)
+
+	if this.changedCells != nil {
+		this.changedCells[coord] = true
+	}
 
 	return nil
 }
 
+func (this *World) ActivateCell(coord Coord) error {
+	return this.SetCell(coord, LiveCell)
+}
+
 func (this *World) ForEachCoordinate(f func(Coord)) {
 	for c, _ := range this.ActiveMatrix {
-		f(c)
+		if _, fixed := this.fixedCells[c]; !fixed {
+			f(c)
+		}
 	}
 }
 
-func (this *World) GetCellNeighboursCoords(coord Coord) NeighboursCoords {
-	validCoords := make(NeighboursCoords, 0, 8)
+// Where a neighbour really is, if it is in the world at all. In triangular
+// grids, wrapping around cannot turn an up triangle into a down one, so
+// circular triangular worlds only wrap fully when both sides are even
+func (this *World) transform(coord Coord) (Coord, bool) {
+	t := this.NeighbourCoordTransformation(coord)
+
+	if _, fixed := this.fixedCells[t]; fixed {
+		return t, true
+	}
+
+	if !this.IsCoordValid(t) {
+		return t, false
+	}
+
+	if this.Grid == TriangularGrid && IsUpTriangle(coord) != IsUpTriangle(t) {
+		return t, false
+	}
+
+	return t, true
+}
+
+func (this *World) validNeighboursCoords(neighbours []Coord) NeighboursCoords {
+	validCoords := make(NeighboursCoords, 0, len(neighbours))
+
+	for _, n := range neighbours {
+		if t, valid := this.transform(n); valid {
+			validCoords = append(validCoords, t)
+		}
+	}
+
+	return validCoords
+}
+
+func (this *World) neighbourOffsetsAt(coord Coord) []Coord {
+	x, y := coord.Get()
+	return this.neighbourOffsets[(x+y)&1]
+}
 
-	for _, n := range []Coord{
+// Same as GetCellNeighboursCoords, for a neighbourhood other than the world's one
+func (this *World) GetCellNeighboursCoordsIn(coord Coord, neighbourhood Neighbourhood) NeighboursCoords {
+	x, y := coord.Get()
+
+	neighbours := make([]Coord, 0)
+
+	for _, offset := range neighbourhood.OffsetsAt(coord) {
+		dx, dy := offset.Get()
+		neighbours = append(neighbours, NewCoord(x+dx, y+dy))
+	}
+
+	return this.validNeighboursCoords(neighbours)
+}
+
+// Clockwise, starting on the north-west corner
+func mooreNeighbours(coord Coord) []Coord {
+	return []Coord{
 		coord.NorthWest(),
 		coord.North(),
 		coord.NorthEast(),
@@ -121,14 +247,181 @@ func (this *World) GetCellNeighboursCoords(coord Coord) NeighboursCoords {
 		coord.South(),
 		coord.SouthWest(),
 		coord.West(),
-	} {
-		t := this.NeighbourCoordTransformation(n)
-		if this.IsCoordValid(t) {
-			validCoords = append(validCoords, t)
+	}
+}
+
+// Also sets the neighbourhood to the default one of the grid
+func (this *World) SetGrid(grid Grid) {
+	this.Grid = grid
+	this.SetNeighbourhood(grid.DefaultNeighbourhood())
+}
+
+// The cells already in the world get their new neighbours tracked,
+// so that they are visited on the next step
+func (this *World) SetNeighbourhood(neighbourhood Neighbourhood) {
+	this.Neighbourhood = neighbourhood
+	this.neighbourOffsets = [2][]Coord{neighbourhood.OffsetsAt(NewCoord(0, 0)), neighbourhood.OffsetsAt(NewCoord(1, 0))}
+	this.neighbourWeights = make([]int, len(this.neighbourOffsets[0]))
+
+	for i, offset := range this.neighbourOffsets[0] {
+		this.neighbourWeights[i] = neighbourhood.Weight(offset)
+	}
+
+	lives := make([]Coord, 0)
+
+	this.ForEachCoordinate(func(coord Coord) {
+		if this.ActiveMatrix.GetCell(coord) != DeadCell {
+			lives = append(lives, coord)
+		}
+	})
+
+	for _, coord := range lives {
+		for _, n := range this.GetCellDependentsCoords(coord) {
+			this.ActiveMatrix.track(n)
 		}
 	}
 
-	return validCoords
+	this.borderDependents = this.liveBorderDependents()
+
+	for _, coord := range this.borderDependents {
+		this.ActiveMatrix.track(coord)
+	}
+}
+
+func (this *World) GetCellNeighboursCoords(coord Coord) NeighboursCoords {
+	x, y := coord.Get()
+
+	offsets := this.neighbourOffsetsAt(coord)
+
+	neighbours := make([]Coord, 0, len(offsets))
+
+	for _, offset := range offsets {
+		dx, dy := offset.Get()
+		neighbours = append(neighbours, NewCoord(x+dx, y+dy))
+	}
+
+	return this.validNeighboursCoords(neighbours)
+}
+
+// The cells that have this one as a neighbour, which are the same
+// as its neighbours unless the neighbourhood is asymmetric
+func (this *World) GetCellDependentsCoords(coord Coord) NeighboursCoords {
+	// Triangular neighbourhoods are always symmetric
+	if this.Neighbourhood.IsTriangular() {
+		return this.GetCellNeighboursCoords(coord)
+	}
+
+	x, y := coord.Get()
+
+	dependents := make([]Coord, 0, len(this.neighbourOffsets[0]))
+
+	for _, offset := range this.neighbourOffsets[0] {
+		dx, dy := offset.Get()
+		dependents = append(dependents, NewCoord(x-dx, y-dy))
+	}
+
+	return this.validNeighboursCoords(dependents)
+}
+
+// The sum of the weights of the live neighbours, which is just how many
+// they are unless the neighbourhood is a custom one. A custom neighbourhood
+// can also give a weight to the cell itself
+func (this *World) CountLiveNeighbours(coord Coord) int {
+	x, y := coord.Get()
+
+	count := 0
+
+	if this.ActiveMatrix.IsLive(coord) {
+		count += this.Neighbourhood.MiddleWeight()
+	}
+
+	for i, offset := range this.neighbourOffsetsAt(coord) {
+		dx, dy := offset.Get()
+
+		n, valid := this.transform(NewCoord(x+dx, y+dy))
+
+		if valid && this.ActiveMatrix.IsLive(n) {
+			count += this.neighbourWeights[i]
+		}
+	}
+
+	return count
+}
+
+// Which of the 8 neighbours are live, as the bits of a byte, the highest
+// one being the north-west neighbour and going clockwise from there
+func (this *World) GetCellNeighbourhoodConfiguration(coord Coord) uint8 {
+	configuration := uint8(0)
+
+	for _, n := range mooreNeighbours(coord) {
+		configuration <<= 1
+
+		t, valid := this.transform(n)
+
+		if valid && this.ActiveMatrix.IsLive(t) {
+			configuration |= 1
+		}
+	}
+
+	return configuration
+}
+
+// Clockwise, starting on the north-west corner, which is the upper left
+// neighbour once the rows are staggered, as the printer does
+func hexagonalNeighbours(coord Coord) []Coord {
+	return []Coord{
+		coord.NorthWest(),
+		coord.North(),
+		coord.East(),
+		coord.SouthEast(),
+		coord.South(),
+		coord.West(),
+	}
+}
+
+// The 6 neighbours in a hexagonal grid
+func (this *World) GetCellHexagonalNeighboursCoords(coord Coord) NeighboursCoords {
+	return this.validNeighboursCoords(hexagonalNeighbours(coord))
+}
+
+// Only the orthogonal neighbours
+func (this *World) GetCellVonNeumannNeighboursCoords(coord Coord) NeighboursCoords {
+	return this.validNeighboursCoords([]Coord{
+		coord.North(),
+		coord.East(),
+		coord.South(),
+		coord.West(),
+	})
+}
+
+func (this *World) GetGrid() Grid {
+	return this.Grid
+}
+
+func (this *World) ForEachLiveCell(f func(Coord)) {
+	this.ForEachCoordinate(func(coord Coord) {
+		if this.ActiveMatrix.IsLive(coord) {
+			f(coord)
+		}
+	})
+}
+
+func (this *World) ForEachCell(f func(Coord, Cell)) {
+	this.ForEachCoordinate(func(coord Coord) {
+		if cell := this.ActiveMatrix.GetCell(coord); cell != DeadCell {
+			f(coord, cell)
+		}
+	})
+}
+
+func (this *World) Population() uint64 {
+	population := uint64(0)
+
+	this.ForEachLiveCell(func(Coord) {
+		population++
+	})
+
+	return population
 }
 
 func (this *World) GetCellLiveNeighboursCoords(coord Coord) NeighboursCoords {
@@ -143,6 +436,55 @@ func (this *World) GetCellLiveNeighboursCoords(coord Coord) NeighboursCoords {
 	return lives
 }
 
+// The smallest rectangle with all the cells that are not dead,
+// given by its top left and bottom right corners
+func (this *World) Bounds() (topLeft, bottomRight Coord, found bool) {
+	for coord, cell := range this.ActiveMatrix {
+		if _, fixed := this.fixedCells[coord]; fixed || cell == DeadCell {
+			continue
+		}
+
+		if !found {
+			topLeft, bottomRight, found = coord, coord, true
+			continue
+		}
+
+		x, y := coord.Get()
+
+		if x < topLeft[0] {
+			topLeft[0] = x
+		}
+
+		if y < topLeft[1] {
+			topLeft[1] = y
+		}
+
+		if x > bottomRight[0] {
+			bottomRight[0] = x
+		}
+
+		if y > bottomRight[1] {
+			bottomRight[1] = y
+		}
+	}
+
+	return topLeft, bottomRight, found
+}
+
 func (this *World) Size() (h, w int) {
 	return this.Height, this.Width
 }
+
+// With cells of its own, changed apart from this world's
+func (this *World) Copy() World {
+	world := *this
+	world.ActiveMatrix = CreateMatrix()
+	world.InactiveMatrix = CreateMatrix()
+	world.changedCells = nil
+
+	for coord, cell := range this.ActiveMatrix {
+		world.ActiveMatrix[coord] = cell
+	}
+
+	return world
+}
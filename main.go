@@ -8,11 +8,30 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
 	"time"
 )
 
-type ImportedSpecies map[string]string
+// the format can be given as in rle:filename
+func splitFormat(value string) (PatternFormat, string) {
+	if f := strings.SplitN(value, ":", 2); len(f) == 2 {
+		if format, err := ParsePatternFormat(f[0]); err == nil {
+			return format, f[1]
+		}
+	}
+
+	return "", value
+}
+
+type ImportedSpecie struct {
+	// Empty when the format should be detected
+	Format   PatternFormat
+	Filename string
+}
+
+type ImportedSpecies map[string]ImportedSpecie
 
 func (this *ImportedSpecies) Set(value string) error {
 	if *this == nil {
@@ -29,7 +48,9 @@ func (this *ImportedSpecies) Set(value string) error {
 		return errors.New(fmt.Sprintf("Cannot define imported life \"%s\" more than once", value))
 	}
 
-	(*this)[s[0]] = s[1]
+	format, filename := splitFormat(s[1])
+
+	(*this)[s[0]] = ImportedSpecie{format, filename}
 
 	return nil
 }
@@ -38,14 +59,141 @@ func (this *ImportedSpecies) String() string {
 	return "[]"
 }
 
+// Macrocell files placed straight into the HashLife engine
+type MacrocellLife struct {
+	Specie   string
+	Content  string
+	Position Coord
+}
+
+// Exits when the file cannot be read or its format is unknown
+func readPatternFile(imported ImportedSpecie) (PatternFormat, string) {
+	filename := imported.Filename
+
+	fileContent, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not open file %s: \"%s\"\n", filename, err)
+		os.Exit(3)
+	}
+
+	format := imported.Format
+
+	if format == "" {
+		format, err = DetectPatternFormat(filename, string(fileContent))
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(4)
+		}
+	}
+
+	return format, string(fileContent)
+}
+
+// Exits when the file cannot be imported
+func importPattern(importer Importer, imported ImportedSpecie) Pattern {
+	format, content := readPatternFile(imported)
+
+	pattern, err := importer.Import(format, content)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not import from file %s: \"%s\"\n", imported.Filename, err)
+		os.Exit(4)
+	}
+
+	return pattern
+}
+
+// Tells what becomes of the pattern in the file, under the given rule or the one it was designed for
+func analyse(option string, ruleOption string) {
+	format, filename := splitFormat(option)
+
+	pattern := importPattern(NewSpecieImporter(), ImportedSpecie{format, filename})
+
+	rule := ruleOption
+
+	if rule == "" {
+		rule = pattern.Rule
+	}
+
+	if rule == "" {
+		rule = DefaultRule
+	}
+
+	ruleset, err := ParseRule(rule)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(describePattern(filename, pattern))
+
+	finding, found, err := AnalysePattern(pattern, ruleset, DefaultAnalysisGenerations)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not analyse the pattern: \"%s\"\n", err)
+		os.Exit(1)
+	}
+
+	if !found {
+		fmt.Printf("Nothing was found in %d generations under rule %s\n", DefaultAnalysisGenerations, ruleset)
+		return
+	}
+
+	fmt.Printf("%s, under rule %s\n", finding, ruleset)
+}
+
+func describePattern(name string, pattern Pattern) string {
+	h, w := pattern.Specie.Size()
+
+	description := fmt.Sprintf("%s (%dx%d)", name, w, h)
+
+	if pattern.Name != "" {
+		description += ": " + pattern.Name
+	}
+
+	if pattern.Author != "" {
+		description += ", by " + pattern.Author
+	}
+
+	if pattern.Year != 0 {
+		description += fmt.Sprintf(", %d", pattern.Year)
+	}
+
+	if pattern.Rule != "" {
+		description += ", rule " + pattern.Rule
+	}
+
+	for _, line := range pattern.Description {
+		description += "\n    " + line
+	}
+
+	return description
+}
+
 func main() {
 	var configFilename string
 	var showHelp bool
+	var showSpecies bool
+	var exportOption string
+	var ruleOption string
+	var detectPeriod bool
+	var analyseOption string
+	var showCensus bool
 	var importedSpecies ImportedSpecies
 
 	flag.BoolVar(&showHelp, "help", false, "Show help message")
 	flag.StringVar(&configFilename, "config", "config.json", "Configuration file path")
-	flag.Var(&importedSpecies, "i", "List of lifename=filename for imported life")
+	flag.BoolVar(&showSpecies, "species", false, "Show the known species and what is known about them")
+	flag.Var(&importedSpecies, "i", "List of lifename=[format:]filename for imported life, format being one of plaintext, life105, life106, rle or macrocell")
+
+	flag.StringVar(&ruleOption, "rule", "", "Rule string, as B36/S23, overriding the one in the configuration file")
+	flag.BoolVar(&detectPeriod, "detect", false, "Stop the simulation when the world becomes empty, still or periodic, and tell which")
+	flag.BoolVar(&showCensus, "census", false, "Show the objects in the world, as still lifes, oscillators and spaceships, when the simulation ends")
+	flag.StringVar(&analyseOption, "analyse", "", "Tell what becomes of the pattern in [format:]filename, as its period and speed, instead of running the simulation")
+	flag.StringVar(&exportOption, "o", "", "Save the world as [format:]filename when the simulation ends or is interrupted, format being one of plaintext, life106, rle or macrocell")
 
 	flag.Parse()
 
@@ -54,6 +202,22 @@ func main() {
 		os.Exit(2)
 	}
 
+	if analyseOption != "" {
+		analyse(analyseOption, ruleOption)
+		os.Exit(0)
+	}
+
+	exportFormat, exportFilename := splitFormat(exportOption)
+
+	if exportFilename != "" && exportFormat == "" {
+		var err error
+
+		if exportFormat, err = PatternFormatFromFilename(exportFilename); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(2)
+		}
+	}
+
 	fileBytes, err := ioutil.ReadFile(configFilename)
 
 	if err != nil {
@@ -68,13 +232,74 @@ func main() {
 		os.Exit(1)
 	}
 
-	world, _ := func() (World, error) {
-		if config.Circular {
-			return NewCircularWorld(config.Size.Height, config.Size.Width)
+	if ruleOption != "" {
+		config.Rule = ruleOption
+	}
+
+	if config.Rule == "" {
+		config.Rule = DefaultRule
+	}
+
+	ruleset, err := ParseRule(config.Rule)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	if len(config.Neighbourhood) > 0 {
+		neighbourhood, err := NewNeighbourhoodFromMask(config.Neighbourhood)
+
+		if err == nil {
+			ruleset, err = NewCustomNeighbourhoodRule(ruleset, neighbourhood)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	topology, err := ParseTopology(config.Topology)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	world, err := NewTopologyWorld(config.Size.Height, config.Size.Width, topology)
+
+	if config.Boundaries != (Boundaries{}) {
+		if topology.Type != PlaneTopology {
+			fmt.Fprintf(os.Stderr, "Boundaries only apply to plane worlds\n")
+			os.Exit(1)
 		}
 
-		return NewWorld(config.Size.Height, config.Size.Width)
-	}()
+		world, err = NewBoundedWorld(world.Height, world.Width, config.Boundaries)
+	}
+
+	if err == nil && world.Unbounded && (config.Size.Height <= 0 || config.Size.Width <= 0) {
+		err = errors.New("The size of the part of an unbounded world that is shown is missing")
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not create the world: %s\n", err)
+		os.Exit(1)
+	}
+
+	grid, err := ParseGrid(config.Grid)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	if !grid.Supports(ruleset) {
+		fmt.Fprintf(os.Stderr, "Rule %s cannot run on a %s grid\n", ruleset, grid)
+		os.Exit(1)
+	}
+
+	world.SetGrid(grid)
 
 	for _, position := range config.Positions {
 		world.ActivateCell(position)
@@ -82,9 +307,16 @@ func main() {
 
 	rand.Seed(time.Now().UnixNano())
 
+	// Over the world, whose size the topology may give, or the part of it that is shown
+	h, w := world.Size()
+
+	if world.Unbounded {
+		h, w = config.Size.Height, config.Size.Width
+	}
+
 	for i := 0; i < config.RandomCells; i++ {
-		x := rand.Int() % config.Size.Width
-		y := rand.Int() % config.Size.Height
+		x := rand.Int() % w
+		y := rand.Int() % h
 
 		world.ActivateCell(NewCoord(x, y))
 	}
@@ -92,56 +324,197 @@ func main() {
 	importer := NewSpecieImporter()
 
 	if config.Species == nil {
-		config.Species = make(map[string]Specie)
+		config.Species = make(map[string]Pattern)
 	}
 
-	for lifeName, filename := range importedSpecies {
-		fileContent, err := ioutil.ReadFile(filename)
+	// The HashLife engine loads macrocell files itself, as huge patterns would not fit in a specie
+	macrocells := make(map[string]string)
 
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Could not open file %s: \"%s\"\n", filename, err)
-			os.Exit(3)
+	for lifeName, imported := range importedSpecies {
+		if config.Engine == "hashlife" && !showSpecies {
+			if format, content := readPatternFile(imported); format == MacrocellFormat {
+				macrocells[lifeName] = content
+				continue
+			}
 		}
 
-		config.Species[lifeName], err = importer.ImportFromString(string(fileContent))
+		config.Species[lifeName] = importPattern(importer, imported)
+	}
 
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Could not import from file %s: \"%s\"\n", filename, err)
-			os.Exit(4)
+	if showSpecies {
+		names := make([]string, 0, len(config.Species))
+
+		for name := range config.Species {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Println(describePattern(name, config.Species[name]))
 		}
+
+		os.Exit(0)
 	}
 
 	placer := NewLifePlacer(&world)
+	macrocellLives := make([]MacrocellLife, 0)
 
 	for _, life := range config.Population {
-		specie, found := config.Species[life.Specie]
+		if content, isMacrocell := macrocells[life.Specie]; isMacrocell {
+			macrocellLives = append(macrocellLives, MacrocellLife{Specie: life.Specie, Content: content, Position: life.Position})
+			continue
+		}
+
+		pattern, found := config.Species[life.Specie]
 
 		if !found {
 			fmt.Fprintf(os.Stderr, "Invalid specie %s\n", life.Specie)
 			os.Exit(1)
 		}
 
-		if err := placer.Place(specie, life.Position); err != nil {
+		if !pattern.RunsUnderRule(ruleset) {
+			fmt.Fprintf(os.Stderr, "Warning: %s was designed for rule %s, but runs under %s\n", life.Specie, pattern.Rule, ruleset)
+		}
+
+		if err := placer.Place(pattern.Specie, life.Position); err != nil {
 			fmt.Fprintf(os.Stderr, "Could not insert %s in position %s: \"%s\"\n", life.Specie, life.Position, err)
 			os.Exit(1)
 		}
 	}
 
-	generator := NewGenerator(&world)
+	if config.StepPow2 >= 64 {
+		fmt.Fprintf(os.Stderr, "Cannot step 2^%d generations at once\n", config.StepPow2)
+		os.Exit(1)
+	}
+
+	engine, err := NewEngine(config.Engine, &world, ruleset)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	if sparse, isSparse := engine.(*SparseEngine); isSparse {
+		sparse.Generator.Workers = config.Workers
+	}
 
-	printer := NewPrinter(&world)
+	for _, life := range macrocellLives {
+		metadata, err := engine.(*HashLifeGenerator).PlaceMacrocell(life.Content, life.Position)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not insert %s in position %s: \"%s\"\n", life.Specie, life.Position, err)
+			os.Exit(1)
+		}
+
+		if !metadata.RunsUnderRule(ruleset) {
+			fmt.Fprintf(os.Stderr, "Warning: %s was designed for rule %s, but runs under %s\n", life.Specie, metadata.Rule, ruleset)
+		}
+	}
+
+	printer := NewPrinter(engine)
+
+	if world.Unbounded {
+		printer = NewViewportPrinter(engine, Viewport{Origin: NewCoord(0, 0), Height: config.Size.Height, Width: config.Size.Width}, true)
+	}
+
+	// Interrupting the simulation should not lose the world to be saved
+	interrupted := make(chan os.Signal, 1)
+
+	if exportFilename != "" {
+		signal.Notify(interrupted, os.Interrupt)
+	}
+
+	detector := NewPeriodDetector(engine)
+	var finding Finding
+	var settled bool
 
 	start := time.Now()
 
+	steps := uint64(0)
+
 	// yes, config.Generations == 0 means infinite loop :-)
-	for i := uint64(0); i < config.Generations || config.Generations == 0; i++ {
+simulation:
+	for ; steps < config.Generations || config.Generations == 0; steps++ {
+		select {
+		case <-interrupted:
+			break simulation
+		default:
+		}
+
 		fmt.Print("\033[2J")
 		fmt.Print(printer.Print())
+
+		// Only the printed generations are compared, the engine
+		// being then stepped further to find the real period
+		if detectPeriod {
+			if finding, settled = detector.Observe(steps << config.StepPow2); settled {
+				break simulation
+			}
+		}
+
 		time.Sleep(time.Duration(config.GenerationDuration))
-		generator.Step()
+		engine.StepN(1 << config.StepPow2)
 	}
 
 	elapsed := time.Since(start)
 
-	fmt.Printf("Using %d steps has taken %s\n", config.Generations, elapsed)
+	if settled {
+		fmt.Println(finding)
+	}
+
+	fmt.Printf("Using %d steps has taken %s\n", steps, elapsed)
+
+	if sparse, isSparse := engine.(*SparseEngine); isSparse {
+		counters := sparse.Generator.Counters
+		fmt.Printf("%d cells were evaluated and %d were skipped, as nothing around them changed\n", counters.EvaluatedCells, counters.SkippedCells)
+	}
+
+	if showCensus {
+		census, err := TakeCensus(engine, ruleset)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Print(census)
+	}
+
+	// Huge patterns are saved straight from the quadtree
+	if hashLife, isHashLife := engine.(*HashLifeGenerator); isHashLife && exportFilename != "" && exportFormat == MacrocellFormat {
+		content := hashLife.Macrocell(PatternMetadata{})
+
+		if err := ioutil.WriteFile(exportFilename, []byte(content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write file %s: \"%s\"\n", exportFilename, err)
+			os.Exit(5)
+		}
+
+		exportFilename = ""
+	}
+
+	if exportFilename != "" {
+		exporter := NewExporter(engine)
+
+		pattern, err := exporter.Capture()
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not save the world: \"%s\"\n", err)
+			os.Exit(5)
+		}
+
+		pattern.Rule = ruleset.String()
+
+		content, err := ExportPattern(exportFormat, pattern)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not save the world: \"%s\"\n", err)
+			os.Exit(5)
+		}
+
+		if err := ioutil.WriteFile(exportFilename, []byte(content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write file %s: \"%s\"\n", exportFilename, err)
+			os.Exit(5)
+		}
+	}
 }